@@ -1,11 +1,31 @@
 package api
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"time"
 
+	"github.com/SufyaanKhateeb/college-placement-app-api/cmd/migrate/migrations"
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/metrics"
+	"github.com/SufyaanKhateeb/college-placement-app-api/middlewares"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/audit"
 	"github.com/SufyaanKhateeb/college-placement-app-api/service/auth"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/calendar"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/health"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/invite"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/oidc"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/placement"
 	"github.com/SufyaanKhateeb/college-placement-app-api/service/user"
+	"github.com/SufyaanKhateeb/college-placement-app-api/stores"
+	"github.com/SufyaanKhateeb/college-placement-app-api/stores/memtx"
+	"github.com/SufyaanKhateeb/college-placement-app-api/tracing"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -13,42 +33,194 @@ import (
 )
 
 type APIServer struct {
-	addr string
-	db   *pgxpool.Pool
+	addr   string
+	cfg    config.Config
+	db     *pgxpool.Pool
+	stores types.Stores
+	tx     types.Transactor
+	health *health.Handler
 }
 
-func NewAPIServer(addr string, db *pgxpool.Pool) *APIServer {
+func NewAPIServer(cfg config.Config, db *pgxpool.Pool) *APIServer {
 	return &APIServer{
-		addr: addr,
-		db:   db,
+		addr:   ":" + cfg.Server.Port,
+		cfg:    cfg,
+		db:     db,
+		stores: stores.Postgres(db),
+		tx:     stores.NewPostgresTransactor(db),
+		health: health.NewHandler(5 * time.Second),
 	}
 }
 
-func (s *APIServer) Run() error {
+// NewDemoAPIServer serves the API from the given stores without a database.
+// Nothing is persisted across restarts.
+func NewDemoAPIServer(cfg config.Config, demoStores types.Stores) *APIServer {
+	return &APIServer{
+		addr:   ":" + cfg.Server.Port,
+		cfg:    cfg,
+		stores: demoStores,
+		tx:     memtx.New(demoStores),
+		health: health.NewHandler(5 * time.Second),
+	}
+}
+
+// Run serves the API until ctx is cancelled, then stops accepting
+// connections and waits up to the shutdown timeout for in-flight requests.
+func (s *APIServer) Run(ctx context.Context) error {
+	if s.db != nil {
+		migrationVersion, err := migrations.LatestVersion()
+		if err != nil {
+			return err
+		}
+		s.health.AddCheck("database", health.DbCheck(s.db))
+		s.health.AddCheck("migrations", health.MigrationCheck(s.db, migrationVersion))
+
+		if err := metrics.RegisterPool(s.db); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Metrics.Addr != "" {
+		if err := s.runMetricsServer(ctx); err != nil {
+			return err
+		}
+	}
+
+	srv := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.routes(),
+		ReadTimeout:       s.cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: s.cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      s.cfg.Server.WriteTimeout,
+		IdleTimeout:       s.cfg.Server.IdleTimeout,
+	}
+
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("listening", "addr", s.addr)
+
+	beforeShutdown := func() {
+		// fail readiness first and give load balancers time to notice
+		s.health.SetShuttingDown()
+		time.Sleep(s.cfg.Server.ShutdownDelay)
+	}
+	return serve(ctx, srv, ln, s.cfg.Server.ShutdownTimeout, beforeShutdown)
+}
+
+// runMetricsServer exposes /metrics on its own listener, which can be kept
+// off the public network instead of guarding it with a token.
+func (s *APIServer) runMetricsServer(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	srv := &http.Server{
+		Addr:              s.cfg.Metrics.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: s.cfg.Server.ReadHeaderTimeout,
+	}
+
+	ln, err := net.Listen("tcp", s.cfg.Metrics.Addr)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("serving metrics", "addr", s.cfg.Metrics.Addr)
+
+	go func() {
+		if err := serve(ctx, srv, ln, s.cfg.Server.ShutdownTimeout, nil); err != nil {
+			slog.Error("metrics server failed", "err", err)
+		}
+	}()
+	return nil
+}
+
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, shutdownTimeout time.Duration, beforeShutdown func()) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down, draining in-flight requests")
+	if beforeShutdown != nil {
+		beforeShutdown()
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *APIServer) routes() http.Handler {
 	r := chi.NewRouter()
-	r.Use(middleware.Logger)
+	r.Use(middlewares.ForwardedFor(s.cfg.Server.TrustedProxyPrefixes()))
+	r.Use(middlewares.RequestId)
+	r.Use(middlewares.Tenant)
+	r.Use(middlewares.AccessLog)
+	r.Use(metrics.Middleware)
+	r.Use(tracing.Middleware)
 	r.Use(middleware.Recoverer)
 
 	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:5173"},
+		AllowedOrigins:   s.cfg.CORS.AllowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "traceparent", "tracestate"},
 		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300, // Maximum value not ignored by any of major browsers
+		AllowCredentials: s.cfg.CORS.AllowCredentials,
+		MaxAge:           s.cfg.CORS.MaxAge,
 	}))
 
+	r.Use(middlewares.AuditMiddleware(s.stores.Audit))
+	r.Use(middlewares.DbTimeout(s.cfg.Database.QueryTimeout))
+	r.Use(middlewares.MaxBodyBytes(s.cfg.Server.MaxBodyBytes))
+
+	// mounted routers inherit these, so every unmatched route gets a problem response
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("no route for %s", r.URL.Path)))
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusMethodNotAllowed, utils.CodeMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method)))
+	})
+
+	s.health.RegisterRoutes(r)
+	if s.cfg.Metrics.Addr == "" && s.cfg.Metrics.Token != "" {
+		r.Handle("/metrics", metrics.RequireToken(s.cfg.Metrics.Token, metrics.Handler()))
+	}
+
 	subRouter := chi.NewRouter()
 
-	userStore := user.NewStore(s.db)
-	authStore := auth.NewAuthStore(s.db)
-	authService := auth.NewAuthService(*authStore)
-	userHandler := user.NewHandler(userStore, authService)
+	authService := auth.NewAuthService(s.stores.Auth, s.cfg.Auth)
+	var provider types.OIDCProvider
+	if s.cfg.OIDC.Enabled {
+		provider = oidc.NewClient(s.cfg.OIDC, nil)
+	}
+	userHandler := user.NewHandler(s.stores.User, s.tx, authService, authService, s.cfg.Auth, s.cfg.Cookie, s.cfg.Registration, s.cfg.OIDC, provider)
 	userHandler.RegisterRoutes(subRouter)
 
-	r.Mount("/api/v1", subRouter)
+	placementEvents := placement.NewCalendarSource(s.stores.Placement, s.stores.User)
+	calendarHandler := calendar.NewHandler(s.stores.Calendar, authService, s.cfg.Cookie, s.cfg.Server.PublicUrl, placementEvents)
+	calendarHandler.RegisterRoutes(subRouter)
+
+	inviteHandler := invite.NewHandler(s.stores.Invite, s.stores.User, s.tx, authService, authService, s.cfg.Cookie, s.cfg.Registration)
+	inviteHandler.RegisterRoutes(subRouter)
 
-	log.Println("Listening on", s.addr)
+	auditHandler := audit.NewHandler(s.stores.Audit, authService, s.cfg.Cookie, s.cfg.Database.ExportTimeout)
+	auditHandler.RegisterRoutes(subRouter)
+
+	r.Mount("/api/v1", subRouter)
 
-	return http.ListenAndServe(s.addr, r)
+	return r
 }
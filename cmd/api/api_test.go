@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServeGracefulShutdown(t *testing.T) {
+	started := make(chan struct{})
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte("done"))
+		}),
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve(ctx, srv, ln, 5*time.Second, nil)
+	}()
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		res, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			resCh <- result{err: err}
+			return
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		resCh <- result{status: res.StatusCode, body: string(body), err: err}
+	}()
+
+	// shut down while the request is still being handled
+	<-started
+	cancel()
+
+	res := <-resCh
+	if res.err != nil {
+		t.Fatalf("expected in-flight request to complete, got %v", res.err)
+	}
+	if res.status != http.StatusOK || res.body != "done" {
+		t.Errorf("expected in-flight request to succeed, got %d %q", res.status, res.body)
+	}
+
+	if err := <-serveErr; err != nil {
+		t.Errorf("expected clean shutdown, got %v", err)
+	}
+
+	if _, err := http.Get("http://" + ln.Addr().String()); err == nil {
+		t.Error("expected new connections to be refused after shutdown")
+	}
+}
@@ -2,31 +2,133 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/SufyaanKhateeb/college-placement-app-api/cmd/api"
+	"github.com/SufyaanKhateeb/college-placement-app-api/cmd/migrate/migrations"
 	"github.com/SufyaanKhateeb/college-placement-app-api/config"
 	"github.com/SufyaanKhateeb/college-placement-app-api/db"
+	"github.com/SufyaanKhateeb/college-placement-app-api/jobs"
+	"github.com/SufyaanKhateeb/college-placement-app-api/logging"
+	"github.com/SufyaanKhateeb/college-placement-app-api/stores"
+	"github.com/SufyaanKhateeb/college-placement-app-api/tracing"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
 )
 
 func main() {
-	config.InitConfig()
-	dbpool, err := db.NewDbPool(config.Env.DbUrl)
+	// run returns instead of exiting so its deferred cleanup always happens
+	if err := run(); err != nil {
+		slog.Error("exiting", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	printConfig := fs.Bool("print-config", false, "print the effective config with secrets redacted and exit")
+	demo := fs.Bool("demo", false, "serve from in-memory stores without Postgres, nothing is persisted")
+	cfg, err := config.Load(fs, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if *printConfig {
+		return cfg.Print(os.Stdout)
+	}
+
+	if err := logging.Setup(cfg.Log.Level, cfg.Log.Format); err != nil {
+		return err
+	}
+	if len(cfg.Registration.StudentDomains) == 0 {
+		slog.Warn("registration.studentDomains is empty, students cannot register")
+	}
+
+	if err := cfg.Auth.LoadKeys(); err != nil {
+		if !*demo {
+			return err
+		}
+		slog.Warn("using a generated key pair, tokens will not survive a restart", "err", err)
+		if err := cfg.Auth.GenerateKeys(); err != nil {
+			return err
+		}
+	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, "placement-app-api", cfg.Tracing.Exporter, cfg.Tracing.File)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	// deferred first so pending spans are flushed after everything else stopped
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownTracing(flushCtx)
+	}()
+
+	var server *api.APIServer
+	var jobStore types.JobStore
+	if *demo {
+		demoStores := stores.Memory()
+		server = api.NewDemoAPIServer(cfg, demoStores)
+		jobStore = demoStores.Job
+		slog.Warn("running in demo mode, all data is kept in memory")
 	} else {
-		err := dbpool.Ping(context.Background())
+		dbpool, err := db.NewDbPool(cfg.Database)
 		if err != nil {
-			log.Fatal(err)
+			return err
+		}
+		defer dbpool.Close()
+
+		if err := dbpool.Ping(ctx); err != nil {
+			return err
+		}
+		slog.Info("connected to database")
+
+		if cfg.Database.AutoMigrate {
+			if err := migrations.Up(cfg.Database.Url); err != nil {
+				return fmt.Errorf("applying migrations: %w", err)
+			}
+			slog.Info("database migrated")
 		}
-		log.Println("Successfully connected to database")
+
+		server = api.NewAPIServer(cfg, dbpool)
+		jobStore = jobs.NewStore(dbpool)
 	}
 
-	defer dbpool.Close()
+	var worker *jobs.Worker
+	if cfg.Worker.Enabled {
+		worker = jobs.NewWorker(jobStore, cfg.Worker.Concurrency)
+		scheduler := jobs.NewScheduler(jobStore)
+		if err := jobs.RegisterBuiltins(worker, scheduler, jobStore); err != nil {
+			return err
+		}
 
-	server := api.NewAPIServer(":"+config.Env.Port, dbpool)
-	if err := server.Run(); err != nil {
-		log.Fatal(err)
+		worker.Start()
+		go scheduler.Run(ctx)
+		slog.Info("background worker started")
 	}
+
+	err = server.Run(ctx)
+
+	// requests have drained, background jobs go next and the pool is closed last
+	if worker != nil {
+		drainCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := worker.Stop(drainCtx); err != nil {
+			slog.Warn("worker did not drain in time", "err", err)
+		}
+	}
+
+	return err
 }
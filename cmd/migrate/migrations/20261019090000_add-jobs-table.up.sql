@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGSERIAL NOT NULL,
+    kind VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    maxAttempts INTEGER NOT NULL DEFAULT 5,
+    runAt TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    lockedAt TIMESTAMPTZ,
+    uniqueKey VARCHAR(255),
+    lastError TEXT,
+    createdAt TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id),
+    UNIQUE (uniqueKey)
+);
+
+CREATE INDEX IF NOT EXISTS jobs_claim_idx ON jobs (kind, status, runAt);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    id SERIAL NOT NULL,
+    userId INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    tokenHash VARCHAR(64) NOT NULL,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revokedAt TIMESTAMP,
+
+    PRIMARY KEY (id),
+    UNIQUE (tokenHash)
+);
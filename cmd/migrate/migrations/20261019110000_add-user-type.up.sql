@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS uType VARCHAR(32) NOT NULL DEFAULT 'student';
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL NOT NULL,
+    actorId INTEGER NOT NULL DEFAULT 0,
+    actorRole VARCHAR(32) NOT NULL DEFAULT '',
+    action VARCHAR(255) NOT NULL,
+    entityType VARCHAR(64) NOT NULL DEFAULT '',
+    entityId VARCHAR(64) NOT NULL DEFAULT '',
+    status INTEGER NOT NULL DEFAULT 0,
+    changes TEXT NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    userAgent TEXT NOT NULL DEFAULT '',
+    createdAt TIMESTAMPTZ NOT NULL,
+    prevHash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_actor_idx ON audit_events (actorId, createdAt);
+CREATE INDEX IF NOT EXISTS audit_events_entity_idx ON audit_events (entityType, entityId, createdAt);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
@@ -0,0 +1,20 @@
+-- Emails used to be compared exactly, so the same address may exist in several
+-- spellings. The oldest account keeps the address, later ones are parked on a
+-- placeholder that still shows the original so an admin can merge them. The
+-- normalization matches utils.NormalizeEmail, including Unicode NFC, and trims
+-- the same characters as strings.TrimSpace rather than only spaces.
+CREATE OR REPLACE FUNCTION pg_temp.normalize_email(email text) RETURNS text AS $$
+    SELECT normalize(lower(btrim(email, E' \t\n\x0B\f\r\u0085\u00A0\u1680\u2000\u2001\u2002\u2003\u2004\u2005\u2006\u2007\u2008\u2009\u200A\u2028\u2029\u202F\u205F\u3000')), NFC)
+$$ LANGUAGE sql IMMUTABLE;
+
+UPDATE users u
+SET email = 'duplicate-' || u.id || '+' || pg_temp.normalize_email(u.email)
+WHERE EXISTS (
+    SELECT 1 FROM users o
+    WHERE pg_temp.normalize_email(o.email) = pg_temp.normalize_email(u.email) AND o.id < u.id
+);
+
+UPDATE users SET email = pg_temp.normalize_email(email) WHERE email <> pg_temp.normalize_email(email);
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (lower(email));
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS invites;
+ALTER TABLE users DROP COLUMN IF EXISTS company;
@@ -0,0 +1,20 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS company VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS invites (
+    id SERIAL NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    uType VARCHAR(32) NOT NULL,
+    company VARCHAR(255) NOT NULL DEFAULT '',
+    createdBy INTEGER NOT NULL,
+    sendCount INTEGER NOT NULL DEFAULT 1,
+    lastSentAt TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expiresAt TIMESTAMPTZ NOT NULL,
+    acceptedAt TIMESTAMPTZ,
+    acceptedUserId INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    revokedAt TIMESTAMPTZ,
+    createdAt TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS invites_email_idx ON invites (lower(email));
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id SERIAL NOT NULL,
+    userId INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    userAgent VARCHAR(512) NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    createdAt TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    lastSeenAt TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revokedAt TIMESTAMPTZ,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_idx ON sessions (userId);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS users_branch_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS passwordChangeRequired;
+ALTER TABLE users DROP COLUMN IF EXISTS deletedAt;
+ALTER TABLE users DROP COLUMN IF EXISTS disabledAt;
+ALTER TABLE users DROP COLUMN IF EXISTS branch;
@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS branch VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabledAt TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletedAt TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS passwordChangeRequired BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS users_branch_idx ON users (branch);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS interview_slots;
+DROP TABLE IF EXISTS applications;
+DROP TABLE IF EXISTS drives;
+DROP TABLE IF EXISTS companies;
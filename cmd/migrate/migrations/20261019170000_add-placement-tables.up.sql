@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS companies (
+    id SERIAL NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    domain VARCHAR(255) NOT NULL DEFAULT '',
+    createdAt TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS drives (
+    id SERIAL NOT NULL,
+    companyId INTEGER NOT NULL REFERENCES companies (id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    eligibleBranches VARCHAR(64)[] NOT NULL DEFAULT '{}',
+    applicationDeadline TIMESTAMPTZ NOT NULL,
+    talkAt TIMESTAMPTZ,
+    talkLocation VARCHAR(255) NOT NULL DEFAULT '',
+    createdAt TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS drives_company_idx ON drives (companyId);
+
+CREATE TABLE IF NOT EXISTS applications (
+    id SERIAL NOT NULL,
+    driveId INTEGER NOT NULL REFERENCES drives (id) ON DELETE CASCADE,
+    studentId INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status VARCHAR(32) NOT NULL DEFAULT 'applied',
+    createdAt TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id),
+    UNIQUE (driveId, studentId)
+);
+
+CREATE INDEX IF NOT EXISTS applications_student_idx ON applications (studentId);
+
+CREATE TABLE IF NOT EXISTS interview_slots (
+    id SERIAL NOT NULL,
+    applicationId INTEGER NOT NULL REFERENCES applications (id) ON DELETE CASCADE,
+    startsAt TIMESTAMPTZ NOT NULL,
+    endsAt TIMESTAMPTZ NOT NULL,
+    location VARCHAR(255) NOT NULL DEFAULT '',
+    createdAt TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS interview_slots_application_idx ON interview_slots (applicationId);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS companies_domain_idx;
+DROP TABLE IF EXISTS offers;
+DROP TABLE IF EXISTS student_profiles;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS student_profiles (
+    userId INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    cgpa NUMERIC(4, 2) NOT NULL,
+    graduationYear INTEGER NOT NULL,
+    updatedAt TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (userId)
+);
+
+CREATE TABLE IF NOT EXISTS offers (
+    id SERIAL NOT NULL,
+    applicationId INTEGER NOT NULL REFERENCES applications (id) ON DELETE CASCADE,
+    ctc BIGINT NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'extended',
+    createdAt TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS offers_application_idx ON offers (applicationId);
+CREATE INDEX IF NOT EXISTS companies_domain_idx ON companies (lower(domain));
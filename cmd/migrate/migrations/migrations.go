@@ -0,0 +1,97 @@
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+type Migration struct {
+	Version uint
+	Name    string
+}
+
+// List returns the embedded migrations ordered by version.
+func List() ([]Migration, error) {
+	entries, err := fs.ReadDir(FS, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Migration
+	for _, e := range entries {
+		base, ok := strings.CutSuffix(e.Name(), ".up.sql")
+		if !ok {
+			continue
+		}
+		prefix, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %s", e.Name())
+		}
+		v, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %s: %w", e.Name(), err)
+		}
+		list = append(list, Migration{Version: uint(v), Name: name})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list, nil
+}
+
+// LatestVersion returns the version of the newest migration, which is what a
+// fully migrated database reports.
+func LatestVersion() (uint, error) {
+	list, err := List()
+	if err != nil || len(list) == 0 {
+		return 0, err
+	}
+	return list[len(list)-1].Version, nil
+}
+
+// New returns a migrator that reads the embedded migrations, so it works
+// regardless of the working directory.
+func New(dbUrl string) (*migrate.Migrate, error) {
+	src, err := iofs.New(FS, ".")
+	if err != nil {
+		return nil, err
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", src, dbUrl)
+	if err != nil {
+		// the driver quotes the url, password included, in its errors
+		if u, perr := url.Parse(dbUrl); perr == nil {
+			return nil, errors.New(strings.ReplaceAll(err.Error(), dbUrl, u.Redacted()))
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+// Up applies every pending migration. Concurrent callers are serialised by
+// the driver's advisory lock.
+func Up(dbUrl string) error {
+	m, err := New(dbUrl)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"fmt"
+	"io/fs"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	list, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) == 0 {
+		t.Fatal("expected embedded migrations")
+	}
+
+	for i, m := range list {
+		if i > 0 && m.Version <= list[i-1].Version {
+			t.Errorf("migrations out of order at %d", m.Version)
+		}
+		down := fmt.Sprintf("%d_%s.down.sql", m.Version, m.Name)
+		if _, err := fs.Stat(FS, down); err != nil {
+			t.Errorf("missing down migration %s", down)
+		}
+	}
+
+	latest, err := LatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != list[len(list)-1].Version {
+		t.Errorf("expected latest version %d, got %d", list[len(list)-1].Version, latest)
+	}
+}
@@ -0,0 +1,72 @@
+package migrations_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/cmd/migrate/migrations"
+	"github.com/SufyaanKhateeb/college-placement-app-api/testdb"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestMain(m *testing.M) {
+	testdb.Main(m)
+}
+
+func TestNormalizeUserEmails(t *testing.T) {
+	ctx := context.Background()
+	dbUrl := testdb.NewSchema(t)
+
+	m, err := migrations.New(dbUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	// the version right before the normalization
+	if err := m.Migrate(20261019110100); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := pgx.Connect(ctx, dbUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+
+	// José, jose and rene spell é composed, decomposed and decomposed again,
+	// Carol is surrounded by whitespace that strings.TrimSpace removes
+	for _, email := range []string{"Alice@College.edu", "alice@college.edu ", "bob@college.edu", "Jos\u00e9@college.edu", "jose\u0301@college.edu", "rene\u0301@college.edu", "\u00a0Carol@college.edu\t\n"} {
+		_, err := conn.Exec(ctx, "insert into users (firstName, lastName, email, password) values ('f', 'l', $1, 'hash')", email)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := conn.Query(ctx, "select email from users order by id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	emails, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"alice@college.edu", "duplicate-2+alice@college.edu", "bob@college.edu", "jos\u00e9@college.edu", "duplicate-5+jos\u00e9@college.edu", "ren\u00e9@college.edu", "carol@college.edu"}
+	if len(emails) != len(want) {
+		t.Fatalf("expected %v, got %v", want, emails)
+	}
+	for i := range want {
+		if emails[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, emails)
+			break
+		}
+	}
+
+	_, err = conn.Exec(ctx, "insert into users (firstName, lastName, email, password) values ('f', 'l', 'BOB@college.edu', 'hash')")
+	if err == nil {
+		t.Error("expected the index to reject a differently cased duplicate")
+	}
+}
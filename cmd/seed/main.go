@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/db"
+	"github.com/SufyaanKhateeb/college-placement-app-api/logging"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/auth"
+	"github.com/SufyaanKhateeb/college-placement-app-api/stores"
+)
+
+// seedPassword is shared by every seeded account so testers can log in as
+// anyone.
+const seedPassword = "Password@123"
+
+func main() {
+	if err := run(); err != nil {
+		slog.Error("seeding failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	seed := fs.Uint64("seed", 42, "random seed, the same seed always produces the same data")
+	reset := fs.Bool("reset", false, "delete previously seeded data before seeding")
+	var c counts
+	fs.IntVar(&c.admins, "admins", 1, "number of admins")
+	fs.IntVar(&c.officers, "officers", 2, "number of placement officers")
+	fs.IntVar(&c.recruiters, "recruiters", 5, "number of recruiters")
+	fs.IntVar(&c.students, "students", 50, "number of students")
+	fs.IntVar(&c.companies, "companies", 8, "number of companies")
+	fs.IntVar(&c.drives, "drives", 2, "number of drives per company")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: seed [flags]\n\nseeded accounts use the password %s\n\nflags:\n", seedPassword)
+		fs.PrintDefaults()
+	}
+
+	cfg, err := config.Load(fs, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := logging.Setup(cfg.Log.Level, "text"); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	dbpool, err := db.NewDbPool(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer dbpool.Close()
+
+	st := stores.Postgres(dbpool)
+	tx := stores.NewPostgresTransactor(dbpool)
+
+	if *reset {
+		companies, users, err := resetSeeded(ctx, db.NewTxManager(dbpool))
+		if err != nil {
+			return err
+		}
+		slog.Info("removed seeded data", "companies", companies, "users", users)
+	}
+
+	// hashing once keeps seeding fast, bcrypt is deliberately slow
+	passwordHash, err := auth.HashPassword(ctx, seedPassword)
+	if err != nil {
+		return err
+	}
+
+	rng := rand.New(rand.NewPCG(*seed, *seed))
+	now := time.Now()
+	users := generateUsers(rng, c)
+	profiles := generateProfiles(rng, users, now)
+	companies := generatePlacements(rng, c, users, now)
+
+	created, err := seedUsers(ctx, st.User, users, passwordHash)
+	if err != nil {
+		return err
+	}
+	slog.Info("seeded users", "created", created, "skipped", len(users)-created, "domain", seedDomain)
+
+	if err := seedProfiles(ctx, st, profiles); err != nil {
+		return err
+	}
+	created, err = seedPlacements(ctx, tx, companies)
+	if err != nil {
+		return err
+	}
+	slog.Info("seeded placements", "companies", created, "skipped", len(companies)-created, "profiles", len(profiles))
+	return nil
+}
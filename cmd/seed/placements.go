@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+var companyNames = []string{
+	"Acme Systems", "Globex", "Initech", "Umbrella Labs", "Stark Industries",
+	"Wayne Enterprises", "Hooli", "Tyrell Corp", "Cyberdyne", "Soylent Foods",
+}
+
+var driveTitles = []string{
+	"Software Engineer", "Graduate Engineer Trainee", "Data Analyst",
+	"Hardware Design Engineer", "Site Engineer", "Product Analyst",
+}
+
+var talkLocations = []string{"Main auditorium", "Seminar hall 1", "Seminar hall 2", "Online"}
+
+// applicationStatuses and offerStatuses are handed out in turn, so every
+// state shows up as soon as there are enough applications.
+var applicationStatuses = []string{
+	types.ApplicationStatusApplied,
+	types.ApplicationStatusShortlisted,
+	types.ApplicationStatusInterview,
+	types.ApplicationStatusOffered,
+	types.ApplicationStatusRejected,
+	types.ApplicationStatusWithdrawn,
+}
+
+var offerStatuses = []string{types.OfferStatusExtended, types.OfferStatusAccepted, types.OfferStatusDeclined}
+
+// seedProfile and seedApplication refer to students by email, their ids are
+// only known once they are stored.
+type seedProfile struct {
+	Student string
+	Profile types.StudentProfile
+}
+
+type seedApplication struct {
+	Student   string
+	Status    string
+	Interview *types.InterviewSlot
+	Offer     *types.Offer
+}
+
+type seedDrive struct {
+	Drive        types.Drive
+	Applications []seedApplication
+}
+
+type seedCompany struct {
+	Company types.Company
+	Drives  []seedDrive
+}
+
+// generateProfiles gives every student an academic record in their last two
+// years.
+func generateProfiles(rng *rand.Rand, users []types.User, now time.Time) []seedProfile {
+	var profiles []seedProfile
+	for _, u := range users {
+		if u.UType != types.UTypeStudent {
+			continue
+		}
+		profiles = append(profiles, seedProfile{
+			Student: u.Email,
+			Profile: types.StudentProfile{
+				Cgpa:           6 + float64(rng.IntN(391))/100,
+				GraduationYear: now.Year() + rng.IntN(2),
+			},
+		})
+	}
+	return profiles
+}
+
+// generatePlacements builds the companies with their drives, and the
+// applications of eligible students to them. Dates are relative to the day of
+// now, so some deadlines have passed and others are coming up.
+func generatePlacements(rng *rand.Rand, c counts, users []types.User, now time.Time) []seedCompany {
+	day := now.UTC().Truncate(24 * time.Hour)
+	applied, offered := 0, 0
+
+	var companies []seedCompany
+	for i := 1; i <= c.companies; i++ {
+		name := companyNames[(i-1)%len(companyNames)]
+		if round := (i-1)/len(companyNames) + 1; round > 1 {
+			name = fmt.Sprintf("%s %d", name, round)
+		}
+		company := seedCompany{Company: types.Company{
+			Name:   name,
+			Domain: strings.ReplaceAll(strings.ToLower(name), " ", "-") + "." + seedDomain,
+		}}
+
+		for j := 1; j <= c.drives; j++ {
+			d := types.Drive{
+				Title:               driveTitles[rng.IntN(len(driveTitles))],
+				ApplicationDeadline: day.AddDate(0, 0, rng.IntN(60)-20).Add(18*time.Hour + 30*time.Minute),
+			}
+			// a third of the drives are open to every branch
+			if rng.IntN(3) > 0 {
+				for _, k := range rng.Perm(len(branches))[:2+rng.IntN(2)] {
+					d.EligibleBranches = append(d.EligibleBranches, branches[k])
+				}
+				slices.Sort(d.EligibleBranches)
+			}
+			if rng.IntN(2) == 0 {
+				talkAt := d.ApplicationDeadline.AddDate(0, 0, -3-rng.IntN(5)).Add(-8 * time.Hour)
+				d.TalkAt = &talkAt
+				d.TalkLocation = talkLocations[rng.IntN(len(talkLocations))]
+			}
+
+			drive := seedDrive{Drive: d}
+			for _, u := range users {
+				eligible := len(d.EligibleBranches) == 0 || slices.Contains(d.EligibleBranches, u.Branch)
+				if u.UType != types.UTypeStudent || !eligible || rng.IntN(2) == 0 {
+					continue
+				}
+
+				a := seedApplication{Student: u.Email, Status: applicationStatuses[applied%len(applicationStatuses)]}
+				applied++
+				switch a.Status {
+				case types.ApplicationStatusInterview, types.ApplicationStatusOffered, types.ApplicationStatusRejected:
+					start := d.ApplicationDeadline.Truncate(24*time.Hour).AddDate(0, 0, 3+rng.IntN(8)).
+						Add(10*time.Hour + time.Duration(rng.IntN(12))*30*time.Minute)
+					a.Interview = &types.InterviewSlot{
+						StartsAt: start,
+						EndsAt:   start.Add(30 * time.Minute),
+						Location: fmt.Sprintf("Placement cell, room %d", 1+rng.IntN(6)),
+					}
+				}
+				if a.Status == types.ApplicationStatusOffered {
+					a.Offer = &types.Offer{
+						Ctc:    int64(4+rng.IntN(37)) * 100000,
+						Status: offerStatuses[offered%len(offerStatuses)],
+					}
+					offered++
+				}
+				drive.Applications = append(drive.Applications, a)
+			}
+			company.Drives = append(company.Drives, drive)
+		}
+		companies = append(companies, company)
+	}
+	return companies
+}
+
+// seedProfiles stores the profiles, overwriting earlier ones, so seeding twice
+// is harmless.
+func seedProfiles(ctx context.Context, s types.Stores, profiles []seedProfile) error {
+	for _, p := range profiles {
+		u, err := s.User.GetUserByEmail(ctx, p.Student)
+		if err != nil {
+			return fmt.Errorf("getting student %s: %w", p.Student, err)
+		}
+		p.Profile.UserId = u.Id
+		if err := s.Placement.UpsertStudentProfile(ctx, p.Profile); err != nil {
+			return fmt.Errorf("storing profile of %s: %w", p.Student, err)
+		}
+	}
+	return nil
+}
+
+// seedPlacements creates the companies whose domain is not taken yet, each
+// with everything under it in one transaction, so seeding twice is harmless.
+// It returns how many companies were created.
+func seedPlacements(ctx context.Context, tx types.Transactor, companies []seedCompany) (int, error) {
+	created := 0
+	for _, c := range companies {
+		// fn may be retried, so it reports rather than counts
+		var isNew bool
+		err := tx.InTx(ctx, func(s types.Stores) error {
+			isNew = false
+			_, err := s.Placement.GetCompanyByDomain(ctx, c.Company.Domain)
+			if err == nil {
+				return nil
+			}
+			if !errors.Is(err, types.ErrNotFound) {
+				return err
+			}
+
+			companyId, err := s.Placement.CreateCompany(ctx, c.Company)
+			if err != nil {
+				return err
+			}
+			for _, d := range c.Drives {
+				d.Drive.CompanyId = companyId
+				driveId, err := s.Placement.CreateDrive(ctx, d.Drive)
+				if err != nil {
+					return err
+				}
+				for _, a := range d.Applications {
+					if err := seedApplicationTo(ctx, s, driveId, a); err != nil {
+						return err
+					}
+				}
+			}
+			isNew = true
+			return nil
+		})
+		if err != nil {
+			return created, fmt.Errorf("creating %s: %w", c.Company.Name, err)
+		}
+		if isNew {
+			created++
+		}
+	}
+	return created, nil
+}
+
+func seedApplicationTo(ctx context.Context, s types.Stores, driveId int, a seedApplication) error {
+	u, err := s.User.GetUserByEmail(ctx, a.Student)
+	if err != nil {
+		return fmt.Errorf("getting student %s: %w", a.Student, err)
+	}
+	appId, err := s.Placement.CreateApplication(ctx, types.Application{DriveId: driveId, StudentId: u.Id, Status: a.Status})
+	if err != nil {
+		return err
+	}
+	if a.Interview != nil {
+		slot := *a.Interview
+		slot.ApplicationId = appId
+		if _, err := s.Placement.CreateInterviewSlot(ctx, slot); err != nil {
+			return err
+		}
+	}
+	if a.Offer != nil {
+		offer := *a.Offer
+		offer.ApplicationId = appId
+		if _, err := s.Placement.CreateOffer(ctx, offer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
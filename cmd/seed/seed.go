@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/db"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/jackc/pgx/v5"
+)
+
+// seedDomain marks every seeded account so reset never touches real users.
+const seedDomain = "seed.placement.test"
+
+var firstNames = []string{
+	"Aarav", "Aditi", "Arjun", "Diya", "Farhan", "Ishaan", "Kavya", "Meera",
+	"Nikhil", "Priya", "Rahul", "Riya", "Sahil", "Sana", "Tanvi", "Vikram",
+	"Yash", "Zoya", "Ananya", "Kabir",
+}
+
+var lastNames = []string{
+	"Sharma", "Iyer", "Khan", "Patel", "Reddy", "Nair", "Gupta", "Menon",
+	"Joshi", "Das", "Kulkarni", "Singh", "Bose", "Rao", "Chopra", "Shaikh",
+}
+
+// branches are handed out to students in turn rather than drawn from the rng,
+// so the branch a student gets does not shift the rest of the seeded data.
+var branches = []string{"CSE", "ECE", "EEE", "MECH", "CIVIL", "IT"}
+
+type counts struct {
+	admins     int
+	officers   int
+	recruiters int
+	students   int
+	companies  int
+	// drives per company
+	drives int
+}
+
+// generateUsers builds the seed users. The same rng seed always yields the
+// same users in the same order.
+func generateUsers(rng *rand.Rand, c counts) []types.User {
+	var users []types.User
+	add := func(uType string, n int) {
+		for i := 1; i <= n; i++ {
+			first := firstNames[rng.IntN(len(firstNames))]
+			last := lastNames[rng.IntN(len(lastNames))]
+			u := types.User{
+				FirstName: first,
+				LastName:  last,
+				Email:     fmt.Sprintf("%s.%s.%s%d@%s", strings.ToLower(first), strings.ToLower(last), uType, i, seedDomain),
+				UType:     uType,
+			}
+			if uType == types.UTypeStudent {
+				u.Branch = branches[(i-1)%len(branches)]
+			}
+			users = append(users, u)
+		}
+	}
+
+	add(types.UTypeAdmin, c.admins)
+	add(types.UTypeOfficer, c.officers)
+	add(types.UTypeRecruiter, c.recruiters)
+	add(types.UTypeStudent, c.students)
+	return users
+}
+
+// seedUsers creates the users that do not exist yet, so seeding twice is
+// harmless. It returns how many were created.
+func seedUsers(ctx context.Context, store types.UserStore, users []types.User, passwordHash string) (int, error) {
+	created := 0
+	for _, u := range users {
+		exists, err := store.CheckUserWithEmailExits(ctx, u.Email)
+		if err != nil {
+			return created, err
+		}
+		if exists {
+			continue
+		}
+
+		u.Password = passwordHash
+		if _, err := store.CreateUser(ctx, u); err != nil {
+			return created, fmt.Errorf("creating %s: %w", u.Email, err)
+		}
+		created++
+	}
+	return created, nil
+}
+
+// resetSeeded removes the seeded companies and users for good, the foreign
+// keys take everything that belongs to them along. Both live under
+// seedDomain, so real data is never touched. The stores only soft delete, so
+// this runs its own SQL.
+func resetSeeded(ctx context.Context, tm *db.TxManager) (companies, users int64, err error) {
+	err = tm.InTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, "delete from companies where lower(domain) = $1 or lower(domain) like $2", seedDomain, "%."+seedDomain)
+		if err != nil {
+			return fmt.Errorf("deleting seeded companies: %w", err)
+		}
+		companies = tag.RowsAffected()
+
+		tag, err = tx.Exec(ctx, "delete from users where lower(email) like $1", "%@"+seedDomain)
+		if err != nil {
+			return fmt.Errorf("deleting seeded users: %w", err)
+		}
+		users = tag.RowsAffected()
+		return nil
+	})
+	return companies, users, err
+}
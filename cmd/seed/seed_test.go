@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/db"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/user"
+	"github.com/SufyaanKhateeb/college-placement-app-api/stores"
+	"github.com/SufyaanKhateeb/college-placement-app-api/stores/memtx"
+	"github.com/SufyaanKhateeb/college-placement-app-api/testdb"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+func TestMain(m *testing.M) {
+	testdb.Main(m)
+}
+
+func TestGenerateUsersIsDeterministic(t *testing.T) {
+	c := counts{admins: 1, officers: 2, recruiters: 3, students: 10}
+
+	a := generateUsers(rand.New(rand.NewPCG(7, 7)), c)
+	b := generateUsers(rand.New(rand.NewPCG(7, 7)), c)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("expected the same seed to produce the same users")
+	}
+	if len(a) != 16 {
+		t.Fatalf("expected 16 users, got %d", len(a))
+	}
+
+	roles := map[string]int{}
+	emails := map[string]bool{}
+	for _, u := range a {
+		roles[u.UType]++
+		if emails[u.Email] {
+			t.Errorf("duplicate email %s", u.Email)
+		}
+		emails[u.Email] = true
+		if !strings.HasSuffix(u.Email, "@"+seedDomain) {
+			t.Errorf("expected seeded email, got %s", u.Email)
+		}
+		if (u.UType == types.UTypeStudent) != (u.Branch != "") {
+			t.Errorf("expected only students to have a branch, got %+v", u)
+		}
+	}
+	if roles[types.UTypeAdmin] != 1 || roles[types.UTypeOfficer] != 2 || roles[types.UTypeRecruiter] != 3 || roles[types.UTypeStudent] != 10 {
+		t.Errorf("unexpected role counts %v", roles)
+	}
+}
+
+func TestSeedUsersSkipsExisting(t *testing.T) {
+	store := user.NewMemoryStore()
+	users := generateUsers(rand.New(rand.NewPCG(1, 1)), counts{students: 5})
+
+	created, err := seedUsers(context.Background(), store, users, "hash")
+	if err != nil || created != 5 {
+		t.Fatalf("expected 5 users created, got %d (%v)", created, err)
+	}
+
+	created, err = seedUsers(context.Background(), store, users, "hash")
+	if err != nil || created != 0 {
+		t.Errorf("expected reseeding to create nothing, got %d (%v)", created, err)
+	}
+}
+
+func TestGeneratePlacements(t *testing.T) {
+	c := counts{students: 30, companies: 4, drives: 3}
+	now := time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC)
+	generate := func() ([]seedProfile, []seedCompany) {
+		rng := rand.New(rand.NewPCG(7, 7))
+		users := generateUsers(rng, c)
+		return generateProfiles(rng, users, now), generatePlacements(rng, c, users, now)
+	}
+
+	profiles, companies := generate()
+	otherProfiles, otherCompanies := generate()
+	if !reflect.DeepEqual(profiles, otherProfiles) || !reflect.DeepEqual(companies, otherCompanies) {
+		t.Error("expected the same seed to produce the same placements")
+	}
+
+	if len(profiles) != 30 {
+		t.Errorf("expected a profile per student, got %d", len(profiles))
+	}
+	for _, p := range profiles {
+		if p.Profile.Cgpa < 6 || p.Profile.Cgpa > 10 || p.Profile.GraduationYear < 2024 || p.Profile.GraduationYear > 2025 {
+			t.Errorf("unexpected profile %+v", p)
+		}
+	}
+
+	if len(companies) != 4 {
+		t.Fatalf("expected 4 companies, got %d", len(companies))
+	}
+	statuses := map[string]int{}
+	offers := map[string]int{}
+	for _, company := range companies {
+		if !strings.HasSuffix(company.Company.Domain, "."+seedDomain) {
+			t.Errorf("expected a seeded domain, got %s", company.Company.Domain)
+		}
+		if len(company.Drives) != 3 {
+			t.Errorf("expected 3 drives, got %d", len(company.Drives))
+		}
+		for _, d := range company.Drives {
+			for _, a := range d.Applications {
+				statuses[a.Status]++
+				if (a.Offer != nil) != (a.Status == types.ApplicationStatusOffered) {
+					t.Errorf("expected only offered applications to have an offer, got %+v", a)
+				}
+				if a.Offer != nil {
+					offers[a.Offer.Status]++
+				}
+			}
+		}
+	}
+	for _, status := range applicationStatuses {
+		if statuses[status] == 0 {
+			t.Errorf("expected applications in state %s, got %v", status, statuses)
+		}
+	}
+	for _, status := range offerStatuses {
+		if offers[status] == 0 {
+			t.Errorf("expected offers in state %s, got %v", status, offers)
+		}
+	}
+}
+
+func TestSeedPlacements(t *testing.T) {
+	ctx := context.Background()
+	st := stores.Memory()
+	tx := memtx.New(st)
+
+	c := counts{admins: 1, recruiters: 2, students: 20, companies: 3, drives: 2}
+	rng := rand.New(rand.NewPCG(1, 1))
+	now := time.Now()
+	users := generateUsers(rng, c)
+	profiles := generateProfiles(rng, users, now)
+	companies := generatePlacements(rng, c, users, now)
+
+	if _, err := seedUsers(ctx, st.User, users, "hash"); err != nil {
+		t.Fatal(err)
+	}
+	if err := seedProfiles(ctx, st, profiles); err != nil {
+		t.Fatal(err)
+	}
+	created, err := seedPlacements(ctx, tx, companies)
+	if err != nil || created != 3 {
+		t.Fatalf("expected 3 companies created, got %d (%v)", created, err)
+	}
+	created, err = seedPlacements(ctx, tx, companies)
+	if err != nil || created != 0 {
+		t.Errorf("expected reseeding to create nothing, got %d (%v)", created, err)
+	}
+
+	applications := 0
+	for _, p := range profiles {
+		u, err := st.User.GetUserByEmail(ctx, p.Student)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := st.Placement.GetStudentProfile(ctx, u.Id); err != nil {
+			t.Errorf("expected a profile for %s, got %v", p.Student, err)
+		}
+		a, err := st.Placement.ListApplications(ctx, u.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		applications += len(a)
+	}
+	want := 0
+	for _, company := range companies {
+		for _, d := range company.Drives {
+			want += len(d.Applications)
+		}
+	}
+	if applications == 0 || applications != want {
+		t.Errorf("expected %d applications stored once, got %d", want, applications)
+	}
+}
+
+func TestResetSeeded(t *testing.T) {
+	ctx := context.Background()
+	pool := testdb.New(t)
+	st := stores.Postgres(pool)
+
+	c := counts{admins: 1, recruiters: 2, students: 10, companies: 2, drives: 2}
+	rng := rand.New(rand.NewPCG(1, 1))
+	now := time.Now()
+	users := generateUsers(rng, c)
+	profiles := generateProfiles(rng, users, now)
+	companies := generatePlacements(rng, c, users, now)
+
+	if _, err := seedUsers(ctx, st.User, users, "hash"); err != nil {
+		t.Fatal(err)
+	}
+	if err := seedProfiles(ctx, st, profiles); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := seedPlacements(ctx, stores.NewPostgresTransactor(pool), companies); err != nil {
+		t.Fatal(err)
+	}
+	realId, err := st.User.CreateUser(ctx, types.User{FirstName: "f", LastName: "l", Email: "real@college.edu", Password: "hash", UType: types.UTypeStudent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.Placement.CreateCompany(ctx, types.Company{Name: "Real", Domain: "real.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	deletedCompanies, deletedUsers, err := resetSeeded(ctx, db.NewTxManager(pool))
+	if err != nil || deletedCompanies != 2 || deletedUsers != int64(len(users)) {
+		t.Fatalf("expected 2 companies and %d users removed, got %d and %d (%v)", len(users), deletedCompanies, deletedUsers, err)
+	}
+	if exists, err := st.User.CheckUserWithEmailExits(ctx, users[0].Email); err != nil || exists {
+		t.Errorf("expected seeded users to be gone, got %v (%v)", exists, err)
+	}
+	if _, err := st.Placement.GetCompanyByDomain(ctx, companies[0].Company.Domain); !errors.Is(err, types.ErrNotFound) {
+		t.Errorf("expected seeded companies to be gone, got %v", err)
+	}
+	if _, err := st.User.GetUserById(ctx, realId); err != nil {
+		t.Errorf("expected real users to stay, got %v", err)
+	}
+	if _, err := st.Placement.GetCompanyByDomain(ctx, "real.example.com"); err != nil {
+		t.Errorf("expected real companies to stay, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/db"
+	"github.com/SufyaanKhateeb/college-placement-app-api/jobs"
+	"github.com/SufyaanKhateeb/college-placement-app-api/logging"
+	"github.com/SufyaanKhateeb/college-placement-app-api/tracing"
+)
+
+func main() {
+	if err := run(); err != nil {
+		slog.Error("exiting", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	printConfig := fs.Bool("print-config", false, "print the effective config with secrets redacted and exit")
+	cfg, err := config.Load(fs, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if *printConfig {
+		return cfg.Print(os.Stdout)
+	}
+
+	if err := logging.Setup(cfg.Log.Level, cfg.Log.Format); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, "placement-app-worker", cfg.Tracing.Exporter, cfg.Tracing.File)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownTracing(flushCtx)
+	}()
+
+	dbpool, err := db.NewDbPool(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer dbpool.Close()
+
+	if err := dbpool.Ping(ctx); err != nil {
+		return err
+	}
+	slog.Info("connected to database")
+
+	jobStore := jobs.NewStore(dbpool)
+	worker := jobs.NewWorker(jobStore, cfg.Worker.Concurrency)
+	scheduler := jobs.NewScheduler(jobStore)
+	if err := jobs.RegisterBuiltins(worker, scheduler, jobStore); err != nil {
+		return err
+	}
+
+	worker.Start()
+	go scheduler.Run(ctx)
+	slog.Info("worker started")
+
+	<-ctx.Done()
+	slog.Info("shutting down worker, draining in-flight jobs")
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := worker.Stop(drainCtx); err != nil {
+		slog.Warn("worker did not drain in time", "err", err)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeKeys(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDer, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pvtPath := filepath.Join(dir, "private.key")
+	pubPath := filepath.Join(dir, "public.key")
+	pvt := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	pub := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDer})
+	if err := os.WriteFile(pvtPath, pvt, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubPath, pub, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return pvtPath, pubPath
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	pvtPath, pubPath := writeKeys(t, dir)
+
+	file := filepath.Join(dir, "config.yaml")
+	err := os.WriteFile(file, []byte(`
+server:
+  port: "9000"
+  readTimeout: 20s
+auth:
+  privateKeyPath: `+pvtPath+`
+  publicKeyPath: `+pubPath+`
+cors:
+  allowedOrigins: [https://file.example]
+log:
+  level: debug
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{
+		"CONFIG_FILE":          file,
+		"PORT":                 "9100",
+		"JWT_EXPIRATION_TIME":  "600",
+		"CORS_ALLOWED_ORIGINS": "https://a.example, https://b.example",
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg, err := load(fs, []string{"-server.port", "9200"}, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Server.Port != "9200" {
+		t.Errorf("expected flag to win, got port %s", cfg.Server.Port)
+	}
+	if cfg.Server.ReadTimeout != 20*time.Second {
+		t.Errorf("expected file value, got %s", cfg.Server.ReadTimeout)
+	}
+	if cfg.Server.WriteTimeout != 30*time.Second {
+		t.Errorf("expected default value, got %s", cfg.Server.WriteTimeout)
+	}
+	if cfg.Auth.AccessTokenTTL != 10*time.Minute {
+		t.Errorf("expected bare seconds from env, got %s", cfg.Auth.AccessTokenTTL)
+	}
+	if len(cfg.CORS.AllowedOrigins) != 2 || cfg.CORS.AllowedOrigins[1] != "https://b.example" {
+		t.Errorf("expected origins from env, got %v", cfg.CORS.AllowedOrigins)
+	}
+	if cfg.Log.Level != "debug" {
+		t.Errorf("expected file value, got %s", cfg.Log.Level)
+	}
+	if err := cfg.Auth.LoadKeys(); err != nil {
+		t.Errorf("expected keys to load, got %v", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	unknown := filepath.Join(dir, "unknown.yaml")
+	if err := os.WriteFile(unknown, []byte("server:\n  prot: 80\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want string
+	}{
+		{name: "bad env value", env: map[string]string{"WORKER_CONCURRENCY": "lots"}, want: "env WORKER_CONCURRENCY"},
+		{name: "bad flag value", args: []string{"-server.readTimeout", "soon"}, want: "invalid duration"},
+		{name: "unknown file key", args: []string{"-config", unknown}, want: "prot"},
+		{name: "invalid settings", env: map[string]string{"PORT": "http", "COOKIE_SAME_SITE": "none"}, want: "server.port"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookup := func(key string) (string, bool) {
+				v, ok := tt.env[key]
+				return v, ok
+			}
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(new(bytes.Buffer))
+			_, err := load(fs, tt.args, lookup)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	t.Parallel()
+	cfg := Default()
+	cfg.Cookie.SameSite = "none"
+	cfg.CORS.AllowedOrigins = []string{"*"}
+	cfg.Worker.Concurrency = 0
+	cfg.OIDC.Enabled = true
+	cfg.Server.TrustedProxies = []string{"10.0.0.0/8", "10.0.0.1"}
+	cfg.Registration.InviteUrl = "/accept-invite"
+
+	err := cfg.Validate()
+	for _, want := range []string{"cookie.secure", "cors.allowedOrigins", "worker.concurrency", "oidc.issuer", "oidc.clientId", `server.trustedProxies: "10.0.0.1"`, "registration.inviteUrl"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %s in %v", want, err)
+		}
+	}
+	if err := Default().Validate(); err != nil {
+		t.Errorf("expected defaults to be valid, got %v", err)
+	}
+}
+
+func TestStudentDomainAllowed(t *testing.T) {
+	t.Parallel()
+	closed := RegistrationConfig{}
+	if closed.StudentDomainAllowed("anyone@gmail.com") {
+		t.Error("expected every domain to be refused without an allowlist")
+	}
+
+	cfg := RegistrationConfig{StudentDomains: []string{"College.edu"}}
+	tests := map[string]bool{
+		"alice@college.edu":          true,
+		"bob@cs.college.edu":         true,
+		"eve@notcollege.edu":         false,
+		"eve@college.edu.evil.com":   false,
+		"mallory@gmail.com":          false,
+		"college.edu":                false,
+		"eve@college.edu@gmail.com":  false,
+		"carol@students.college.edu": true,
+	}
+	for email, want := range tests {
+		if got := cfg.StudentDomainAllowed(email); got != want {
+			t.Errorf("StudentDomainAllowed(%q) = %v, want %v", email, got, want)
+		}
+	}
+
+	cfg.StudentDomains = []string{"@college.edu"}
+	if err := (Config{Registration: cfg}).Validate(); err == nil || !strings.Contains(err.Error(), "registration.studentDomains") {
+		t.Errorf("expected an invalid domain error, got %v", err)
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	t.Parallel()
+	cfg := Default()
+	cfg.Database.Url = "postgres://app:hunter2@db:5432/app"
+	cfg.Metrics.Token = "s3cret"
+	cfg.OIDC.ClientSecret = "topsecret"
+
+	var buf bytes.Buffer
+	if err := cfg.Print(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "s3cret") || strings.Contains(out, "topsecret") {
+		t.Errorf("secrets leaked:\n%s", out)
+	}
+	if !strings.Contains(out, "postgres://app:xxxxx@db:5432/app") || !strings.Contains(out, "readTimeout: 15s") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if cfg.Metrics.Token != "s3cret" {
+		t.Error("Print must not modify the config")
+	}
+}
+
+func TestLoadKeysMissingFile(t *testing.T) {
+	t.Parallel()
+	auth := Default().Auth
+	auth.PrivateKeyPath = filepath.Join(t.TempDir(), "missing")
+
+	if err := auth.LoadKeys(); err == nil || !strings.Contains(err.Error(), "reading private key") {
+		t.Errorf("expected missing key error, got %v", err)
+	}
+}
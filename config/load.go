@@ -0,0 +1,269 @@
+package config
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lpernett/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Load registers a flag for every setting on fs, parses args and builds the
+// config from defaults, the file named by -config or CONFIG_FILE, the
+// environment and the parsed flags. Key material is not read, processes that
+// issue tokens call AuthConfig.LoadKeys.
+func Load(fs *flag.FlagSet, args []string) (Config, error) {
+	godotenv.Load()
+	return load(fs, args, os.LookupEnv)
+}
+
+type setting struct {
+	path   string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+type flagValue struct {
+	path  string
+	value string
+}
+
+func load(fs *flag.FlagSet, args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	cfg := Default()
+	settings := settingsOf(&cfg)
+
+	configFile := fs.String("config", "", "path to a YAML config file (env CONFIG_FILE)")
+	var flagValues []flagValue
+	for _, s := range settings {
+		usage := "sets " + s.path
+		if s.env != "" {
+			usage += " (env " + s.env + ")"
+		}
+		fs.Func(s.path, usage, func(v string) error {
+			// parse into a scratch value so bad flags fail during parsing
+			if err := setValue(reflect.New(s.value.Type()).Elem(), v); err != nil {
+				return err
+			}
+			flagValues = append(flagValues, flagValue{path: s.path, value: v})
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := loadFile(&cfg, path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, s := range settings {
+		if s.env == "" {
+			continue
+		}
+		if v, ok := lookupEnv(s.env); ok {
+			if err := setValue(s.value, v); err != nil {
+				return Config{}, fmt.Errorf("env %s: %w", s.env, err)
+			}
+		}
+	}
+
+	byPath := map[string]setting{}
+	for _, s := range settings {
+		byPath[s.path] = s
+	}
+	for _, fv := range flagValues {
+		setValue(byPath[fv.path].value, fv.value)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && err != io.EOF {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// settingsOf flattens cfg into its leaf fields, named by their dotted yaml path.
+func settingsOf(cfg *Config) []setting {
+	var settings []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := f.Tag.Get("yaml")
+			if name == "-" {
+				continue
+			}
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+
+			fv := v.Field(i)
+			if f.Type.Kind() == reflect.Struct {
+				walk(fv, name)
+				continue
+			}
+			settings = append(settings, setting{
+				path:   name,
+				env:    f.Tag.Get("env"),
+				secret: f.Tag.Get("secret") == "true",
+				value:  fv,
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return settings
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setValue parses s into v. Durations also accept a bare number of seconds,
+// which is what the environment variables have always used.
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+			v.SetInt(int64(time.Duration(secs) * time.Second))
+			return nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(i)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// Redacted returns a copy of c that is safe to print. Passwords in urls are
+// masked, other secrets are replaced entirely.
+func (c Config) Redacted() Config {
+	for _, s := range settingsOf(&c) {
+		if !s.secret || s.value.String() == "" {
+			continue
+		}
+		if u, err := url.Parse(s.value.String()); err == nil && u.User != nil {
+			s.value.SetString(u.Redacted())
+			continue
+		}
+		s.value.SetString("REDACTED")
+	}
+	return c
+}
+
+// Print writes the redacted config as YAML.
+func (c Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// LoadKeys reads the RSA key pair from PrivateKeyPath and PublicKeyPath.
+func (c *AuthConfig) LoadKeys() error {
+	var err error
+	if c.PrivateKey, err = loadPrivateKey(c.PrivateKeyPath); err != nil {
+		return err
+	}
+	if c.PublicKey, err = loadPublicKey(c.PublicKeyPath); err != nil {
+		return err
+	}
+	return nil
+}
+
+func loadPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading private key: %w", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+func loadPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading public key: %w", err)
+	}
+	key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// GenerateKeys sets a fresh RSA key pair that only lives as long as the
+// process, tokens signed with it stop verifying after a restart.
+func (c *AuthConfig) GenerateKeys() error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return fmt.Errorf("generating key pair: %w", err)
+	}
+	c.PrivateKey = key
+	c.PublicKey = &key.PublicKey
+	return nil
+}
@@ -3,9 +3,21 @@ package db
 import (
 	"context"
 
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/tracing"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func NewDbPool(url string) (*pgxpool.Pool, error) {
-	return pgxpool.New(context.Background(), url)
+func NewDbPool(cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
+	config, err := pgxpool.ParseConfig(cfg.Url)
+	if err != nil {
+		return nil, err
+	}
+	config.ConnConfig.Tracer = tracing.PgxTracer{}
+	config.MaxConns = cfg.MaxConns
+	config.MinConns = cfg.MinConns
+	config.MaxConnLifetime = cfg.MaxConnLifetime
+	config.MaxConnIdleTime = cfg.MaxConnIdleTime
+
+	return pgxpool.NewWithConfig(context.Background(), config)
 }
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier is what stores run their queries on. *pgxpool.Pool and pgx.Tx both
+// implement it, so a store built on a transaction takes part in it. Begin on a
+// transaction starts a savepoint, letting stores that need their own
+// transaction nest inside an outer one.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// TxBeginner starts transactions, *pgxpool.Pool implements it.
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+}
+
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// TxManager runs functions in serializable transactions, retrying the whole
+// function when Postgres aborts it because of a conflicting transaction.
+type TxManager struct {
+	db          TxBeginner
+	maxAttempts int
+	backoff     time.Duration
+}
+
+func NewTxManager(db TxBeginner) *TxManager {
+	return &TxManager{
+		db:          db,
+		maxAttempts: 5,
+		backoff:     10 * time.Millisecond,
+	}
+}
+
+// InTx calls fn with a new transaction and commits it if fn returns nil. The
+// transaction is rolled back if fn returns an error or panics, the panic is
+// re-raised afterwards. fn may be called more than once, so it must not have
+// side effects outside the transaction.
+func (m *TxManager) InTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = m.run(ctx, fn)
+		if !retryable(err) || attempt == m.maxAttempts {
+			break
+		}
+
+		if ctx.Err() != nil {
+			return errors.Join(err, ctx.Err())
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(m.backoff * time.Duration(attempt)):
+		}
+	}
+	if retryable(err) {
+		return fmt.Errorf("transaction failed after %d attempts: %w", m.maxAttempts, err)
+	}
+	return err
+}
+
+func (m *TxManager) run(ctx context.Context, fn func(tx pgx.Tx) error) (err error) {
+	tx, err := m.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(context.WithoutCancel(ctx))
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		// roll back even when ctx is what made fn fail
+		if rbErr := tx.Rollback(context.WithoutCancel(ctx)); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return errors.Join(err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected
+}
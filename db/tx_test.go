@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeTx records how a transaction ended. The embedded interface is nil, the
+// manager only calls Commit and Rollback.
+type fakeTx struct {
+	pgx.Tx
+	commitErr  error
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	if tx.committed || tx.rolledBack {
+		return pgx.ErrTxClosed
+	}
+	tx.committed = true
+	return tx.commitErr
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	if tx.committed || tx.rolledBack {
+		return pgx.ErrTxClosed
+	}
+	tx.rolledBack = true
+	return nil
+}
+
+type fakeBeginner struct {
+	txs       []*fakeTx
+	commitErr []error
+	opts      pgx.TxOptions
+}
+
+func (b *fakeBeginner) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	b.opts = opts
+	tx := &fakeTx{}
+	if len(b.commitErr) > 0 {
+		tx.commitErr, b.commitErr = b.commitErr[0], b.commitErr[1:]
+	}
+	b.txs = append(b.txs, tx)
+	return tx, nil
+}
+
+func newTestManager(b TxBeginner) *TxManager {
+	tm := NewTxManager(b)
+	tm.backoff = 0
+	return tm
+}
+
+var errConflict = &pgconn.PgError{Code: serializationFailure}
+
+func TestInTxCommits(t *testing.T) {
+	t.Parallel()
+	b := &fakeBeginner{}
+	err := newTestManager(b).InTx(context.Background(), func(tx pgx.Tx) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.txs) != 1 || !b.txs[0].committed {
+		t.Fatalf("expected one committed transaction, got %+v", b.txs)
+	}
+	if b.opts.IsoLevel != pgx.Serializable {
+		t.Errorf("expected a serializable transaction, got %q", b.opts.IsoLevel)
+	}
+}
+
+func TestInTxRollsBackOnError(t *testing.T) {
+	t.Parallel()
+	b := &fakeBeginner{}
+	fnErr := errors.New("boom")
+	err := newTestManager(b).InTx(context.Background(), func(tx pgx.Tx) error {
+		return fnErr
+	})
+	if !errors.Is(err, fnErr) {
+		t.Fatalf("expected %v, got %v", fnErr, err)
+	}
+	if len(b.txs) != 1 || !b.txs[0].rolledBack || b.txs[0].committed {
+		t.Fatalf("expected one rolled back transaction, got %+v", b.txs)
+	}
+}
+
+func TestInTxRollsBackOnPanic(t *testing.T) {
+	t.Parallel()
+	b := &fakeBeginner{}
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Fatalf("expected the panic to be re-raised, got %v", p)
+		}
+		if len(b.txs) != 1 || !b.txs[0].rolledBack {
+			t.Fatalf("expected one rolled back transaction, got %+v", b.txs)
+		}
+	}()
+
+	newTestManager(b).InTx(context.Background(), func(tx pgx.Tx) error {
+		panic("boom")
+	})
+}
+
+func TestInTxRetriesSerializationFailures(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		fnErrs    []error
+		commitErr []error
+		calls     int
+		wantErr   bool
+	}{
+		{name: "conflict in fn", fnErrs: []error{errConflict, errConflict}, calls: 3},
+		{name: "conflict on commit", commitErr: []error{errConflict}, calls: 2},
+		{name: "deadlock", fnErrs: []error{&pgconn.PgError{Code: deadlockDetected}}, calls: 2},
+		{name: "gives up", fnErrs: []error{errConflict, errConflict, errConflict, errConflict, errConflict}, calls: 5, wantErr: true},
+		{name: "other errors are not retried", fnErrs: []error{&pgconn.PgError{Code: "23505"}}, calls: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			b := &fakeBeginner{commitErr: tt.commitErr}
+			calls := 0
+			err := newTestManager(b).InTx(context.Background(), func(tx pgx.Tx) error {
+				calls++
+				if calls <= len(tt.fnErrs) {
+					return tt.fnErrs[calls-1]
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if calls != tt.calls {
+				t.Errorf("expected %d calls, got %d", tt.calls, calls)
+			}
+			if len(b.txs) != tt.calls {
+				t.Errorf("expected %d transactions, got %d", tt.calls, len(b.txs))
+			}
+		})
+	}
+}
+
+func TestInTxStopsRetryingWhenContextIsDone(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := newTestManager(&fakeBeginner{}).InTx(ctx, func(tx pgx.Tx) error {
+		calls++
+		cancel()
+		return errConflict
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Fatalf("expected to stop after 1 call with context.Canceled, got %d calls and %v", calls, err)
+	}
+}
@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+const KindCleanupJobs = "jobs.cleanup"
+
+type CleanupJobsArgs struct {
+	RetentionDays int `json:"retentionDays"`
+}
+
+// RegisterBuiltins wires the jobs that keep the queue itself healthy.
+func RegisterBuiltins(w *Worker, s *Scheduler, store types.JobStore) error {
+	Register(w, KindCleanupJobs, func(ctx context.Context, args CleanupJobsArgs) error {
+		before := time.Now().AddDate(0, 0, -args.RetentionDays)
+		n, err := store.DeleteFinishedJobs(ctx, before)
+		if err != nil {
+			return err
+		}
+		slog.Info("removed finished jobs", "count", n)
+		return nil
+	})
+
+	return s.Add("0 3 * * *", KindCleanupJobs, CleanupJobsArgs{RetentionDays: 7})
+}
@@ -0,0 +1,135 @@
+package jobs
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+type memoryJob struct {
+	job       types.Job
+	lockedAt  time.Time
+	updatedAt time.Time
+}
+
+// MemoryStore is an in-memory types.JobStore for tests and demo mode.
+type MemoryStore struct {
+	mu     sync.Mutex
+	jobs   map[int64]*memoryJob
+	nextId int64
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		jobs:   map[int64]*memoryJob{},
+		nextId: 1,
+	}
+}
+
+func (s *MemoryStore) EnqueueJob(ctx context.Context, job types.Job) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job.UniqueKey != "" {
+		for _, j := range s.jobs {
+			if j.job.UniqueKey == job.UniqueKey {
+				return 0, nil
+			}
+		}
+	}
+
+	now := time.Now()
+	job.Id = s.nextId
+	job.Status = "pending"
+	job.Attempts = 0
+	job.CreatedAt = now
+	s.nextId++
+
+	s.jobs[job.Id] = &memoryJob{job: job, updatedAt: now}
+	return job.Id, nil
+}
+
+func (s *MemoryStore) ClaimJob(ctx context.Context, kinds []string, staleBefore time.Time) (*types.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var runnable []*memoryJob
+	for _, j := range s.jobs {
+		if !slices.Contains(kinds, j.job.Kind) {
+			continue
+		}
+		if j.job.Status == "pending" && !j.job.RunAt.After(now) ||
+			j.job.Status == "running" && j.lockedAt.Before(staleBefore) {
+			runnable = append(runnable, j)
+		}
+	}
+	if len(runnable) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(runnable, func(a, b int) bool {
+		return runnable[a].job.RunAt.Before(runnable[b].job.RunAt)
+	})
+	j := runnable[0]
+	j.job.Status = "running"
+	j.job.Attempts++
+	j.lockedAt = now
+	j.updatedAt = now
+
+	job := j.job
+	return &job, nil
+}
+
+func (s *MemoryStore) CompleteJob(ctx context.Context, id int64) error {
+	return s.update(id, func(j *memoryJob) {
+		j.job.Status = "succeeded"
+		j.job.LastError = ""
+	})
+}
+
+func (s *MemoryStore) RetryJob(ctx context.Context, id int64, runAt time.Time, lastError string) error {
+	return s.update(id, func(j *memoryJob) {
+		j.job.Status = "pending"
+		j.job.RunAt = runAt
+		j.job.LastError = lastError
+	})
+}
+
+func (s *MemoryStore) FailJob(ctx context.Context, id int64, lastError string) error {
+	return s.update(id, func(j *memoryJob) {
+		j.job.Status = "failed"
+		j.job.LastError = lastError
+	})
+}
+
+func (s *MemoryStore) DeleteFinishedJobs(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for id, j := range s.jobs {
+		if (j.job.Status == "succeeded" || j.job.Status == "failed") && j.updatedAt.Before(before) {
+			delete(s.jobs, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// update mirrors an update statement: a missing id is not an error.
+func (s *MemoryStore) update(id int64, fn func(j *memoryJob)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if j, ok := s.jobs[id]; ok {
+		fn(j)
+		j.lockedAt = time.Time{}
+		j.updatedAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/robfig/cron/v3"
+)
+
+type schedule struct {
+	spec     string
+	kind     string
+	args     any
+	schedule cron.Schedule
+}
+
+// Scheduler enqueues jobs on cron-style schedules. Every occurrence is
+// enqueued with a unique key derived from its kind and time, so any number of
+// processes can run a Scheduler without duplicating work.
+type Scheduler struct {
+	store     types.JobStore
+	schedules []schedule
+}
+
+func NewScheduler(store types.JobStore) *Scheduler {
+	return &Scheduler{
+		store: store,
+	}
+}
+
+// Add registers a recurring job using a standard five field cron spec, e.g.
+// "0 3 * * *" for every day at 03:00.
+func (s *Scheduler) Add(spec string, kind string, args any) error {
+	sched, err := cron.ParseStandard(spec)
+	if err != nil {
+		return err
+	}
+
+	s.schedules = append(s.schedules, schedule{
+		spec:     spec,
+		kind:     kind,
+		args:     args,
+		schedule: sched,
+	})
+	return nil
+}
+
+// Run blocks until ctx is cancelled, enqueueing each scheduled job when it is due.
+func (s *Scheduler) Run(ctx context.Context) {
+	if len(s.schedules) == 0 {
+		return
+	}
+
+	now := time.Now()
+	next := make([]time.Time, len(s.schedules))
+	for i, sched := range s.schedules {
+		next[i] = sched.schedule.Next(now)
+	}
+
+	for {
+		earliest := next[0]
+		for _, t := range next[1:] {
+			if t.Before(earliest) {
+				earliest = t
+			}
+		}
+
+		timer := time.NewTimer(time.Until(earliest))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		for i, sched := range s.schedules {
+			if next[i].After(earliest) {
+				continue
+			}
+
+			_, err := Enqueue(ctx, s.store, sched.kind, sched.args, EnqueueOpts{
+				RunAt:     next[i],
+				UniqueKey: UniqueKey(sched.kind, next[i]),
+			})
+			if err != nil {
+				slog.Error("error enqueueing scheduled job", "kind", sched.kind, "spec", sched.spec, "err", err)
+			}
+			next[i] = sched.schedule.Next(next[i])
+		}
+	}
+}
+
+func UniqueKey(kind string, at time.Time) string {
+	return kind + "@" + at.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/db"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/jackc/pgx/v5"
+)
+
+type Store struct {
+	db db.Querier
+}
+
+func NewStore(db db.Querier) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// EnqueueJob inserts a pending job and returns its id. Jobs with a unique key
+// that is already taken are skipped and 0 is returned.
+func (s *Store) EnqueueJob(ctx context.Context, job types.Job) (int64, error) {
+	var uniqueKey *string
+	if job.UniqueKey != "" {
+		uniqueKey = &job.UniqueKey
+	}
+
+	var id int64
+	err := s.db.QueryRow(ctx, `
+		insert into jobs (kind, payload, maxAttempts, runAt, uniqueKey)
+		values ($1, $2, $3, $4, $5)
+		on conflict (uniqueKey) do nothing
+		returning id`,
+		job.Kind, job.Payload, job.MaxAttempts, job.RunAt, uniqueKey,
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// ClaimJob locks the next runnable job of one of the given kinds and marks it
+// as running. Running jobs locked before staleBefore are considered abandoned
+// by a crashed worker and can be claimed again. Returns nil when there is no
+// work.
+func (s *Store) ClaimJob(ctx context.Context, kinds []string, staleBefore time.Time) (*types.Job, error) {
+	j := new(types.Job)
+	err := s.db.QueryRow(ctx, `
+		update jobs
+		set status = 'running', attempts = attempts + 1, lockedAt = now(), updatedAt = now()
+		where id = (
+			select id from jobs
+			where kind = any($1)
+				and ((status = 'pending' and runAt <= now()) or (status = 'running' and lockedAt < $2))
+			order by runAt
+			for update skip locked
+			limit 1
+		)
+		returning id, kind, payload, status, attempts, maxAttempts, runAt, coalesce(uniqueKey, ''), createdAt`,
+		kinds, staleBefore,
+	).Scan(
+		&j.Id,
+		&j.Kind,
+		&j.Payload,
+		&j.Status,
+		&j.Attempts,
+		&j.MaxAttempts,
+		&j.RunAt,
+		&j.UniqueKey,
+		&j.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+func (s *Store) CompleteJob(ctx context.Context, id int64) error {
+	_, err := s.db.Exec(ctx, "update jobs set status = 'succeeded', lockedAt = null, lastError = null, updatedAt = now() where id = $1", id)
+	return err
+}
+
+func (s *Store) RetryJob(ctx context.Context, id int64, runAt time.Time, lastError string) error {
+	_, err := s.db.Exec(ctx, "update jobs set status = 'pending', runAt = $2, lockedAt = null, lastError = $3, updatedAt = now() where id = $1", id, runAt, lastError)
+	return err
+}
+
+func (s *Store) FailJob(ctx context.Context, id int64, lastError string) error {
+	_, err := s.db.Exec(ctx, "update jobs set status = 'failed', lockedAt = null, lastError = $2, updatedAt = now() where id = $1", id, lastError)
+	return err
+}
+
+func (s *Store) DeleteFinishedJobs(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := s.db.Exec(ctx, "delete from jobs where status in ('succeeded', 'failed') and updatedAt < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
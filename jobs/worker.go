@@ -0,0 +1,221 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+const DefaultMaxAttempts = 5
+
+type HandlerFunc func(ctx context.Context, job *types.Job) error
+
+type EnqueueOpts struct {
+	RunAt       time.Time
+	MaxAttempts int
+	UniqueKey   string
+}
+
+// Enqueue marshals args as the job payload and stores a pending job of the
+// given kind.
+func Enqueue(ctx context.Context, store types.JobStore, kind string, args any, opts EnqueueOpts) (int64, error) {
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return 0, err
+	}
+
+	if opts.RunAt.IsZero() {
+		opts.RunAt = time.Now()
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+
+	return store.EnqueueJob(ctx, types.Job{
+		Kind:        kind,
+		Payload:     payload,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       opts.RunAt,
+		UniqueKey:   opts.UniqueKey,
+	})
+}
+
+type Worker struct {
+	store        types.JobStore
+	handlers     map[string]HandlerFunc
+	concurrency  int
+	pollInterval time.Duration
+	lockTimeout  time.Duration
+	// jobTimeout is shorter than lockTimeout so a handler is cancelled before
+	// its lock goes stale and another worker runs the job a second time
+	jobTimeout time.Duration
+
+	stop      chan struct{}
+	runCtx    context.Context
+	runCancel context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+func NewWorker(store types.JobStore, concurrency int) *Worker {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &Worker{
+		store:        store,
+		handlers:     map[string]HandlerFunc{},
+		concurrency:  concurrency,
+		pollInterval: time.Second,
+		lockTimeout:  15 * time.Minute,
+		jobTimeout:   10 * time.Minute,
+	}
+}
+
+// Handle registers fn for jobs of the given kind. It must be called before Start.
+func (w *Worker) Handle(kind string, fn HandlerFunc) {
+	w.handlers[kind] = fn
+}
+
+// Register registers a handler that receives the job payload decoded into T.
+func Register[T any](w *Worker, kind string, fn func(ctx context.Context, args T) error) {
+	w.Handle(kind, func(ctx context.Context, job *types.Job) error {
+		var args T
+		if err := json.Unmarshal(job.Payload, &args); err != nil {
+			return fmt.Errorf("invalid payload for job %s: %w", kind, err)
+		}
+		return fn(ctx, args)
+	})
+}
+
+// Start launches the worker goroutines. Handlers run with a context that is
+// only cancelled if Stop runs out of time, so in-flight jobs are drained
+// rather than interrupted.
+func (w *Worker) Start() {
+	w.stop = make(chan struct{})
+	w.runCtx, w.runCancel = context.WithCancel(context.Background())
+
+	kinds := make([]string, 0, len(w.handlers))
+	for kind := range w.handlers {
+		kinds = append(kinds, kind)
+	}
+
+	for i := 0; i < w.concurrency; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.loop(kinds)
+		}()
+	}
+}
+
+// Stop stops claiming new jobs and waits for in-flight jobs to finish. If ctx
+// expires first, running handlers are cancelled and ctx's error is returned
+// without waiting for them, a handler ignoring its context can't hang
+// shutdown. Jobs it leaves locked are reclaimed once the lock goes stale.
+func (w *Worker) Stop(ctx context.Context) error {
+	if w.stop == nil {
+		return nil
+	}
+	close(w.stop)
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.runCancel()
+		return nil
+	case <-ctx.Done():
+		w.runCancel()
+		return ctx.Err()
+	}
+}
+
+func (w *Worker) loop(kinds []string) {
+	for {
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
+
+		job, err := w.store.ClaimJob(w.runCtx, kinds, time.Now().Add(-w.lockTimeout))
+		if err != nil {
+			slog.Error("error claiming job", "err", err)
+		}
+		if job == nil {
+			select {
+			case <-w.stop:
+				return
+			case <-time.After(w.pollInterval):
+			}
+			continue
+		}
+
+		w.process(job)
+	}
+}
+
+func (w *Worker) process(job *types.Job) {
+	// bookkeeping must survive a cancelled run context, otherwise the job
+	// would stay locked until it goes stale
+	ctx := context.Background()
+
+	logger := slog.With("job_id", job.Id, "kind", job.Kind, "attempt", job.Attempts)
+
+	err := w.run(job)
+	if err == nil {
+		if err := w.store.CompleteJob(ctx, job.Id); err != nil {
+			logger.Error("error completing job", "err", err)
+		}
+		return
+	}
+
+	logger.Warn("job failed", "err", err)
+	if job.Attempts >= job.MaxAttempts {
+		if err := w.store.FailJob(ctx, job.Id, err.Error()); err != nil {
+			logger.Error("error failing job", "err", err)
+		}
+		return
+	}
+
+	if err := w.store.RetryJob(ctx, job.Id, time.Now().Add(Backoff(job.Attempts)), err.Error()); err != nil {
+		logger.Error("error retrying job", "err", err)
+	}
+}
+
+func (w *Worker) run(job *types.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	handler, ok := w.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler registered for job kind %s", job.Kind)
+	}
+	ctx, cancel := context.WithTimeout(w.runCtx, w.jobTimeout)
+	defer cancel()
+	return handler(ctx, job)
+}
+
+// Backoff returns the delay before the next attempt, doubling from 10 seconds
+// and capped at one hour.
+func Backoff(attempts int) time.Duration {
+	delay := 10 * time.Second
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= time.Hour {
+			return time.Hour
+		}
+	}
+	return delay
+}
@@ -0,0 +1,296 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+func TestWorker(t *testing.T) {
+	t.Run("should decode payload and complete job", func(t *testing.T) {
+		store := newMockJobStore()
+		worker := newTestWorker(store)
+
+		got := make(chan string, 1)
+		Register(worker, "greet", func(ctx context.Context, args struct{ Name string }) error {
+			got <- args.Name
+			return nil
+		})
+
+		if _, err := Enqueue(context.Background(), store, "greet", struct{ Name string }{"alice"}, EnqueueOpts{}); err != nil {
+			t.Fatal(err)
+		}
+
+		worker.Start()
+		select {
+		case name := <-got:
+			if name != "alice" {
+				t.Errorf("expected payload name alice, got %s", name)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("job was not processed")
+		}
+		if err := worker.Stop(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		if status := store.status(1); status != "succeeded" {
+			t.Errorf("expected job to succeed, got %s", status)
+		}
+	})
+
+	t.Run("should retry failing job and fail it after max attempts", func(t *testing.T) {
+		store := newMockJobStore()
+		worker := newTestWorker(store)
+
+		worker.Handle("broken", func(ctx context.Context, job *types.Job) error {
+			return fmt.Errorf("boom")
+		})
+
+		if _, err := Enqueue(context.Background(), store, "broken", nil, EnqueueOpts{MaxAttempts: 2}); err != nil {
+			t.Fatal(err)
+		}
+
+		// run attempts one at a time so retries can be made due immediately
+		worker.process(mustClaim(t, store))
+		if status := store.status(1); status != "pending" {
+			t.Fatalf("expected job to be pending for retry, got %s", status)
+		}
+		if store.jobs[1].RunAt.Before(time.Now()) {
+			t.Error("expected retry to be scheduled in the future")
+		}
+
+		store.jobs[1].RunAt = time.Now()
+		worker.process(mustClaim(t, store))
+		if status := store.status(1); status != "failed" {
+			t.Errorf("expected job to be failed, got %s", status)
+		}
+		if store.jobs[1].LastError != "boom" {
+			t.Errorf("expected last error to be recorded, got %q", store.jobs[1].LastError)
+		}
+	})
+
+	t.Run("should turn handler panic into a job error", func(t *testing.T) {
+		store := newMockJobStore()
+		worker := newTestWorker(store)
+
+		worker.Handle("panics", func(ctx context.Context, job *types.Job) error {
+			panic("unexpected")
+		})
+
+		if _, err := Enqueue(context.Background(), store, "panics", nil, EnqueueOpts{MaxAttempts: 1}); err != nil {
+			t.Fatal(err)
+		}
+
+		worker.process(mustClaim(t, store))
+		if status := store.status(1); status != "failed" {
+			t.Errorf("expected job to be failed, got %s", status)
+		}
+	})
+
+	t.Run("should drain in-flight jobs on stop", func(t *testing.T) {
+		store := newMockJobStore()
+		worker := newTestWorker(store)
+
+		started := make(chan struct{})
+		worker.Handle("slow", func(ctx context.Context, job *types.Job) error {
+			close(started)
+			select {
+			case <-time.After(100 * time.Millisecond):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+
+		if _, err := Enqueue(context.Background(), store, "slow", nil, EnqueueOpts{}); err != nil {
+			t.Fatal(err)
+		}
+
+		worker.Start()
+		<-started
+		if err := worker.Stop(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		if status := store.status(1); status != "succeeded" {
+			t.Errorf("expected in-flight job to complete, got %s", status)
+		}
+	})
+
+	t.Run("should give up on handlers ignoring cancellation", func(t *testing.T) {
+		store := newMockJobStore()
+		worker := newTestWorker(store)
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+		worker.Handle("stuck", func(ctx context.Context, job *types.Job) error {
+			close(started)
+			<-release
+			return nil
+		})
+
+		if _, err := Enqueue(context.Background(), store, "stuck", nil, EnqueueOpts{}); err != nil {
+			t.Fatal(err)
+		}
+
+		worker.Start()
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		stopped := make(chan error, 1)
+		go func() { stopped <- worker.Stop(ctx) }()
+		select {
+		case err := <-stopped:
+			if err != context.DeadlineExceeded {
+				t.Errorf("expected the deadline error, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected stop to return at its deadline")
+		}
+	})
+
+	t.Run("should cancel handlers before their lock goes stale", func(t *testing.T) {
+		store := newMockJobStore()
+		worker := newTestWorker(store)
+
+		var deadline time.Time
+		worker.Handle("broken", func(ctx context.Context, job *types.Job) error {
+			deadline, _ = ctx.Deadline()
+			return nil
+		})
+		if _, err := Enqueue(context.Background(), store, "broken", nil, EnqueueOpts{}); err != nil {
+			t.Fatal(err)
+		}
+
+		worker.process(mustClaim(t, store))
+		if deadline.IsZero() || !deadline.Before(time.Now().Add(worker.lockTimeout)) {
+			t.Errorf("expected a deadline within the %s lock, got %v", worker.lockTimeout, deadline)
+		}
+	})
+
+	t.Run("should skip duplicate unique jobs", func(t *testing.T) {
+		store := newMockJobStore()
+		opts := EnqueueOpts{UniqueKey: UniqueKey("report", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))}
+
+		first, _ := Enqueue(context.Background(), store, "report", nil, opts)
+		second, _ := Enqueue(context.Background(), store, "report", nil, opts)
+		if first == 0 || second != 0 {
+			t.Errorf("expected second enqueue to be skipped, got ids %d and %d", first, second)
+		}
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	if Backoff(1) != 10*time.Second {
+		t.Errorf("expected first backoff to be 10s, got %v", Backoff(1))
+	}
+	if Backoff(3) != 40*time.Second {
+		t.Errorf("expected third backoff to be 40s, got %v", Backoff(3))
+	}
+	if Backoff(50) != time.Hour {
+		t.Errorf("expected backoff to be capped at 1h, got %v", Backoff(50))
+	}
+}
+
+func newTestWorker(store types.JobStore) *Worker {
+	w := NewWorker(store, 2)
+	w.pollInterval = 10 * time.Millisecond
+	// tests call process without Start
+	w.runCtx = context.Background()
+	return w
+}
+
+func mustClaim(t *testing.T, store *mockJobStore) *types.Job {
+	t.Helper()
+	job, err := store.ClaimJob(context.Background(), []string{"broken", "panics"}, time.Now())
+	if err != nil || job == nil {
+		t.Fatalf("expected to claim a job, got %v, %v", job, err)
+	}
+	return job
+}
+
+type mockJobStore struct {
+	mu     sync.Mutex
+	nextId int64
+	jobs   map[int64]*types.Job
+}
+
+func newMockJobStore() *mockJobStore {
+	return &mockJobStore{jobs: map[int64]*types.Job{}}
+}
+
+func (s *mockJobStore) status(id int64) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[id].Status
+}
+
+func (s *mockJobStore) EnqueueJob(ctx context.Context, job types.Job) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if job.UniqueKey != "" && j.UniqueKey == job.UniqueKey {
+			return 0, nil
+		}
+	}
+	s.nextId++
+	job.Id = s.nextId
+	job.Status = "pending"
+	s.jobs[job.Id] = &job
+	return job.Id, nil
+}
+
+func (s *mockJobStore) ClaimJob(ctx context.Context, kinds []string, staleBefore time.Time) (*types.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id := int64(1); id <= s.nextId; id++ {
+		j := s.jobs[id]
+		if j.Status != "pending" || j.RunAt.After(time.Now()) {
+			continue
+		}
+		for _, kind := range kinds {
+			if j.Kind == kind {
+				j.Status = "running"
+				j.Attempts++
+				claimed := *j
+				return &claimed, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (s *mockJobStore) CompleteJob(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[id].Status = "succeeded"
+	return nil
+}
+
+func (s *mockJobStore) RetryJob(ctx context.Context, id int64, runAt time.Time, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[id].Status = "pending"
+	s.jobs[id].RunAt = runAt
+	s.jobs[id].LastError = lastError
+	return nil
+}
+
+func (s *mockJobStore) FailJob(ctx context.Context, id int64, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[id].Status = "failed"
+	s.jobs[id].LastError = lastError
+	return nil
+}
+
+func (s *mockJobStore) DeleteFinishedJobs(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
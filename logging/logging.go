@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// sensitiveKeys are attribute keys whose values never reach the logs.
+var sensitiveKeys = map[string]bool{
+	"email":         true,
+	"password":      true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"authorization": true,
+	"cookie":        true,
+}
+
+// Setup installs a logger built by New as the slog default, which also
+// routes the standard log package through it.
+func Setup(level string, format string) error {
+	logger, err := New(os.Stdout, level, format)
+	if err != nil {
+		return err
+	}
+
+	slog.SetDefault(logger)
+	return nil
+}
+
+// New builds a logger writing json or text records at the given level
+// (debug, info, warn or error) with sensitive attributes redacted.
+func New(w io.Writer, level string, format string) (*slog.Logger, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %s", level)
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       l,
+		ReplaceAttr: redact,
+	}
+
+	switch format {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %s", format)
+	}
+}
+
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, "[REDACTED]")
+	}
+	return a
+}
@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Debug("hidden")
+	logger.With("request_id", "abc").Info("login failed", "email", "alice@college.edu", "user_id", 7)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a single json record, got %q", buf.String())
+	}
+
+	if record["email"] != "[REDACTED]" {
+		t.Errorf("expected email to be redacted, got %v", record["email"])
+	}
+	if record["request_id"] != "abc" || record["user_id"] != float64(7) {
+		t.Errorf("expected context attributes to be kept, got %v", record)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "loud", "json"); err == nil {
+		t.Error("expected invalid level to fail")
+	}
+	if _, err := New(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Error("expected invalid format to fail")
+	}
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddleware(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Middleware)
+	router.Get("/calendar/events/{uid}.ics", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	for _, uid := range []string{"a", "b"} {
+		req := httptest.NewRequest(http.MethodGet, "/calendar/events/"+uid+".ics", nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	got := testutil.ToFloat64(httpRequests.WithLabelValues("/calendar/events/{uid}.ics", http.MethodGet, "404"))
+	if got != 2 {
+		t.Errorf("expected 2 requests labelled by route pattern, got %v", got)
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+	handler := RequireToken("secret", Handler())
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status code %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "go_goroutines") {
+		t.Error("expected metrics in response body")
+	}
+}
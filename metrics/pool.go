@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reads pgxpool statistics at scrape time.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns    *prometheus.Desc
+	idleConns        *prometheus.Desc
+	totalConns       *prometheus.Desc
+	maxConns         *prometheus.Desc
+	acquireCount     *prometheus.Desc
+	emptyAcquires    *prometheus.Desc
+	canceledAcquires *prometheus.Desc
+	acquireDuration  *prometheus.Desc
+}
+
+func RegisterPool(pool *pgxpool.Pool) error {
+	return Registry.Register(&poolCollector{
+		pool:             pool,
+		acquiredConns:    prometheus.NewDesc("db_pool_acquired_conns", "Connections currently checked out of the pool.", nil, nil),
+		idleConns:        prometheus.NewDesc("db_pool_idle_conns", "Idle connections in the pool.", nil, nil),
+		totalConns:       prometheus.NewDesc("db_pool_total_conns", "Total connections in the pool.", nil, nil),
+		maxConns:         prometheus.NewDesc("db_pool_max_conns", "Maximum size of the pool.", nil, nil),
+		acquireCount:     prometheus.NewDesc("db_pool_acquires_total", "Successful connection acquires.", nil, nil),
+		emptyAcquires:    prometheus.NewDesc("db_pool_empty_acquires_total", "Acquires that had to wait because the pool was empty.", nil, nil),
+		canceledAcquires: prometheus.NewDesc("db_pool_canceled_acquires_total", "Acquires cancelled by their context.", nil, nil),
+		acquireDuration:  prometheus.NewDesc("db_pool_acquire_wait_seconds_total", "Total time spent waiting to acquire connections.", nil, nil),
+	})
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquireCount
+	ch <- c.emptyAcquires
+	ch <- c.canceledAcquires
+	ch <- c.acquireDuration
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
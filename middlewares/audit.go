@@ -0,0 +1,202 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/netip"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/reqctx"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// AuditMiddleware records an audit event for every data-changing request,
+// every request made by a privileged user and every request whose handler
+// described its target with SetAuditTarget.
+func AuditMiddleware(store types.AuditStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			event := &types.AuditEvent{
+				Action:    r.Method + " " + r.URL.Path,
+				Ip:        ClientIp(r),
+				UserAgent: r.UserAgent(),
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ctx := reqctx.WithAuditEvent(r.Context(), event)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				event.Action = r.Method + " " + rctx.RoutePattern()
+			}
+			event.Status = ww.Status()
+			if event.Status == 0 {
+				event.Status = http.StatusOK
+			}
+
+			if !shouldAudit(r.Method, event) {
+				return
+			}
+
+			// the response is already sent, a cancelled request must not lose its record
+			if err := store.AppendAuditEvent(context.WithoutCancel(r.Context()), *event); err != nil {
+				reqctx.Logger(r.Context()).Error("error recording audit event", "err", err)
+			}
+		})
+	}
+}
+
+// SetAuditTarget describes the entity a request acted on. before and after
+// are diffed field by field into the event's changes; either may be nil.
+func SetAuditTarget(r *http.Request, entityType string, entityId string, before any, after any) {
+	event, ok := reqctx.AuditEventFromContext(r.Context())
+	if !ok {
+		return
+	}
+
+	event.EntityType = entityType
+	event.EntityId = entityId
+	event.Changes = diff(before, after)
+}
+
+func setAuditActor(r *http.Request, claims *types.CustomClaims) {
+	event, ok := reqctx.AuditEventFromContext(r.Context())
+	if !ok {
+		return
+	}
+
+	event.ActorId = claims.Uid
+	event.ActorRole = claims.UType
+}
+
+func shouldAudit(method string, event *types.AuditEvent) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return true
+	}
+
+	if event.EntityType != "" {
+		return true
+	}
+	return event.ActorRole == types.UTypeAdmin || event.ActorRole == types.UTypeOfficer
+}
+
+// redactedFields are never written to the audit log. Only exact names are
+// matched, passwordChangeRequired is a change worth recording.
+var redactedFields = map[string]bool{
+	"password":        true,
+	"currentPassword": true,
+	"newPassword":     true,
+}
+
+type change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+func diff(before any, after any) json.RawMessage {
+	if before == nil && after == nil {
+		return nil
+	}
+
+	from, to := toFields(before), toFields(after)
+	changes := map[string]change{}
+	for k, v := range to {
+		if !reflect.DeepEqual(from[k], v) {
+			changes[k] = change{From: from[k], To: v}
+		}
+	}
+	for k, v := range from {
+		if _, ok := to[k]; !ok {
+			changes[k] = change{From: v, To: nil}
+		}
+	}
+
+	for k := range changes {
+		if redactedFields[k] {
+			changes[k] = change{From: "[redacted]", To: "[redacted]"}
+		}
+	}
+
+	b, err := json.Marshal(changes)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func toFields(v any) map[string]any {
+	fields := map[string]any{}
+	if v == nil {
+		return fields
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return fields
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(b, &fields)
+	return fields
+}
+
+// ClientIp is the address the request came from, without its port. Behind a
+// reverse proxy it is only the client's if ForwardedFor runs first.
+func ClientIp(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ForwardedFor sets the request's RemoteAddr to the client address from
+// X-Forwarded-For when the request comes from one of the trusted proxies. The
+// header is read from the right, entries added by trusted proxies are skipped
+// and the first other one is the client, anything left of it could be forged.
+func ForwardedFor(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	isTrusted := func(addr netip.Addr) bool {
+		return slices.ContainsFunc(trusted, func(p netip.Prefix) bool { return p.Contains(addr.Unmap()) })
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(trusted) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			peer, err := netip.ParseAddr(ClientIp(r))
+			if err != nil || !isTrusted(peer) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var hops []string
+			for _, v := range r.Header.Values("X-Forwarded-For") {
+				hops = append(hops, strings.Split(v, ",")...)
+			}
+			client := peer
+			for i := len(hops) - 1; i >= 0; i-- {
+				addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+				if err != nil {
+					break
+				}
+				client = addr
+				if !isTrusted(addr) {
+					break
+				}
+			}
+
+			r.RemoteAddr = netip.AddrPortFrom(client.Unmap(), 0).String()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/auth"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/go-chi/chi/v5"
+)
+
+// recordingAuditStore keeps appended events and whether their context was
+// already done, the audit package's memory store would be an import cycle.
+type recordingAuditStore struct {
+	types.AuditStore
+	mu        sync.Mutex
+	events    []types.AuditEvent
+	cancelled bool
+}
+
+func (s *recordingAuditStore) AppendAuditEvent(ctx context.Context, e types.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	s.cancelled = s.cancelled || ctx.Err() != nil
+	return nil
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	t.Parallel()
+	cfg := config.Default()
+	if err := cfg.Auth.GenerateKeys(); err != nil {
+		t.Fatal(err)
+	}
+	authService := auth.NewAuthService(auth.NewMemoryStore(nil), cfg.Auth)
+
+	newRouter := func(store *recordingAuditStore) *chi.Mux {
+		r := chi.NewRouter()
+		r.Use(AuditMiddleware(store))
+		r.Get("/public", func(w http.ResponseWriter, r *http.Request) {})
+		r.Post("/public", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(AuthMiddleware(authService, cfg.Cookie), RequireUser)
+			r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+			r.Put("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+				SetAuditTarget(r, "user", chi.URLParam(r, "id"),
+					map[string]any{"firstName": "Ada", "password": "old-hash", "passwordChangeRequired": false, "uType": "student"},
+					map[string]any{"firstName": "Ada", "password": "new-hash", "passwordChangeRequired": true, "uType": "officer"},
+				)
+			})
+		})
+		return r
+	}
+	signedIn := func(uid int, role string) *http.Cookie {
+		t.Helper()
+		sid, err := authService.StartSession(context.Background(), uid, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := authService.SignJwt(time.Minute, types.CustomClaims{Uid: uid, UType: role, Sid: sid})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &http.Cookie{Name: "ACCESS_TOKEN", Value: token}
+	}
+
+	t.Run("records writes and privileged reads", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			method string
+			path   string
+			cookie *http.Cookie
+			want   bool
+		}{
+			{name: "anonymous read", method: http.MethodGet, path: "/public", want: false},
+			{name: "anonymous write", method: http.MethodPost, path: "/public", want: true},
+			{name: "student read", method: http.MethodGet, path: "/users/1", cookie: signedIn(1, types.UTypeStudent), want: false},
+			{name: "recruiter read", method: http.MethodGet, path: "/users/1", cookie: signedIn(2, types.UTypeRecruiter), want: false},
+			{name: "officer read", method: http.MethodGet, path: "/users/1", cookie: signedIn(3, types.UTypeOfficer), want: true},
+			{name: "admin read", method: http.MethodGet, path: "/users/1", cookie: signedIn(4, types.UTypeAdmin), want: true},
+			{name: "student write", method: http.MethodPut, path: "/users/1", cookie: signedIn(1, types.UTypeStudent), want: true},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				store := &recordingAuditStore{}
+				req := httptest.NewRequest(tt.method, tt.path, nil)
+				if tt.cookie != nil {
+					req.AddCookie(tt.cookie)
+				}
+				newRouter(store).ServeHTTP(httptest.NewRecorder(), req)
+
+				if got := len(store.events) == 1; got != tt.want {
+					t.Errorf("expected recorded %v, got %+v", tt.want, store.events)
+				}
+			})
+		}
+	})
+
+	t.Run("records actor, target and redacted changes", func(t *testing.T) {
+		store := &recordingAuditStore{}
+		req := httptest.NewRequest(http.MethodPut, "/users/7", nil)
+		req.RemoteAddr = "203.0.113.9:4000"
+		req.Header.Set("User-Agent", "Firefox")
+		req.AddCookie(signedIn(4, types.UTypeAdmin))
+		newRouter(store).ServeHTTP(httptest.NewRecorder(), req)
+
+		if len(store.events) != 1 {
+			t.Fatalf("expected one event, got %+v", store.events)
+		}
+		e := store.events[0]
+		if e.ActorId != 4 || e.ActorRole != types.UTypeAdmin || e.Action != "PUT /users/{id}" ||
+			e.EntityType != "user" || e.EntityId != "7" || e.Status != http.StatusOK || e.Ip != "203.0.113.9" || e.UserAgent != "Firefox" {
+			t.Errorf("unexpected event %+v", e)
+		}
+
+		var changes map[string]change
+		if err := json.Unmarshal(e.Changes, &changes); err != nil {
+			t.Fatal(err)
+		}
+		if len(changes) != 3 || changes["uType"] != (change{From: "student", To: "officer"}) {
+			t.Errorf("expected only the changed fields, got %s", e.Changes)
+		}
+		if changes["password"] != (change{From: "[redacted]", To: "[redacted]"}) || strings.Contains(string(e.Changes), "hash") {
+			t.Errorf("expected the password to be redacted, got %s", e.Changes)
+		}
+		if changes["passwordChangeRequired"] != (change{From: false, To: true}) {
+			t.Errorf("expected a forced password change to be recorded, got %s", e.Changes)
+		}
+	})
+
+	t.Run("records cancelled requests", func(t *testing.T) {
+		store := &recordingAuditStore{}
+		ctx, cancel := context.WithCancel(context.Background())
+		router := chi.NewRouter()
+		router.Use(AuditMiddleware(store))
+		router.Post("/slow", func(w http.ResponseWriter, r *http.Request) {
+			// the client hangs up while the handler runs
+			cancel()
+		})
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/slow", nil).WithContext(ctx))
+
+		if len(store.events) != 1 || store.cancelled {
+			t.Errorf("expected the event to be stored with a live context, got %+v (cancelled %v)", store.events, store.cancelled)
+		}
+	})
+}
+
+func TestForwardedFor(t *testing.T) {
+	t.Parallel()
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name    string
+		trusted []netip.Prefix
+		peer    string
+		xff     []string
+		want    string
+	}{
+		{name: "direct client", trusted: trusted, peer: "203.0.113.9:4000", xff: []string{"198.51.100.1"}, want: "203.0.113.9"},
+		{name: "no trusted proxies", peer: "10.0.0.1:4000", xff: []string{"198.51.100.1"}, want: "10.0.0.1"},
+		{name: "behind a proxy", trusted: trusted, peer: "10.0.0.1:4000", xff: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "forged entries are ignored", trusted: trusted, peer: "10.0.0.1:4000", xff: []string{"1.1.1.1, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "chained proxies", trusted: trusted, peer: "10.0.0.1:4000", xff: []string{"198.51.100.1, 10.0.0.2", "10.0.0.3"}, want: "198.51.100.1"},
+		{name: "garbage stops the walk", trusted: trusted, peer: "10.0.0.1:4000", xff: []string{"198.51.100.1, nonsense"}, want: "10.0.0.1"},
+		{name: "no header", trusted: trusted, peer: "10.0.0.1:4000", want: "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := ForwardedFor(tt.trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = ClientIp(r)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.peer
+			for _, v := range tt.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("expected client %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -2,15 +2,41 @@ package middlewares
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/metrics"
+	"github.com/SufyaanKhateeb/college-placement-app-api/reqctx"
 	"github.com/SufyaanKhateeb/college-placement-app-api/types"
 	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
 )
 
-func AuthMiddleware(authService types.AuthService) func(http.Handler) http.Handler {
+func AuthMiddleware(authService types.AuthService, cookie config.CookieConfig) func(http.Handler) http.Handler {
+	// sessionActive refuses tokens of sessions that were signed out remotely,
+	// and drops their cookies so the browser stops sending them
+	sessionActive := func(w http.ResponseWriter, r *http.Request, claims *types.CustomClaims) bool {
+		err := authService.CheckSession(r.Context(), claims)
+		if errors.Is(err, types.ErrSessionRevoked) {
+			utils.WriteJwtToCookie(w, "ACCESS_TOKEN", "", time.Duration(0), cookie)
+			utils.WriteJwtToCookie(w, "REFRESH_TOKEN", "", time.Duration(0), cookie)
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return false
+		}
+		if err != nil {
+			utils.WriteInternalError(w, r, "checking session failed", err)
+			return false
+		}
+		return true
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			accessTokenCookie, err := r.Cookie("ACCESS_TOKEN")
@@ -18,55 +44,65 @@ func AuthMiddleware(authService types.AuthService) func(http.Handler) http.Handl
 			if err == nil {
 				token, err := authService.VerifyToken(accessTokenCookie.Value)
 				if err != nil {
-					// utils.WriteJsonError(w, http.StatusTemporaryRedirect, fmt.Errorf("not authorized"))
 					http.Redirect(w, r, "/login", http.StatusFound)
 					return
 				}
 				var ok bool
 				claims, ok = token.Claims.(*types.CustomClaims)
 				if !ok {
-					// utils.WriteJsonError(w, http.StatusUnauthorized, fmt.Errorf("not authorized"))
 					http.Redirect(w, r, "/login", http.StatusFound)
 					return
 				}
+				if !sessionActive(w, r, claims) {
+					return
+				}
 			} else {
 				refreshTokenCookie, err := r.Cookie("REFRESH_TOKEN")
 				if err == nil {
 					token, err := authService.VerifyToken(refreshTokenCookie.Value)
 					if err != nil {
-						// utils.WriteJsonError(w, http.StatusUnauthorized, fmt.Errorf("not authorized"))
 						http.Redirect(w, r, "/login", http.StatusFound)
 						return
 					}
 					var ok bool
 					claims, ok = token.Claims.(*types.CustomClaims)
 					if ok {
-						expirationTime := time.Second * time.Duration(config.Env.JWTExpirationTime)
+						if !sessionActive(w, r, claims) {
+							return
+						}
+						expirationTime := authService.AccessTokenTTL()
 						accessToken, err := authService.SignJwt(expirationTime, types.CustomClaims{
 							Uid:   claims.Uid,
 							UType: claims.UType,
+							Sid:   claims.Sid,
 						})
 						if err != nil {
-							utils.WriteJsonError(w, http.StatusInternalServerError, err)
+							utils.WriteInternalError(w, r, "refreshing access token failed", err)
 							return
 						}
 
-						utils.WriteJwtToCookie(w, "ACCESS_TOKEN", accessToken, expirationTime)
+						utils.WriteJwtToCookie(w, "ACCESS_TOKEN", accessToken, expirationTime, cookie)
+						metrics.TokenRefreshes.Inc()
 					} else {
-						// utils.WriteJsonError(w, http.StatusUnauthorized, fmt.Errorf("not authorized"))
 						http.Redirect(w, r, "/login", http.StatusFound)
 						return
 					}
 				} else {
-					// utils.WriteJsonError(w, http.StatusUnauthorized, fmt.Errorf("not authorized"))
 					http.Redirect(w, r, "/login", http.StatusFound)
 					return
 				}
 			}
-			ctx := context.WithValue(r.Context(), "user", types.UserDto{
-				Id:    claims.Uid,
-				UType: claims.UType,
+			setAuditActor(r, claims)
+			if requestLog, ok := reqctx.RequestLogFromContext(r.Context()); ok {
+				requestLog.UserId = claims.Uid
+			}
+
+			ctx := reqctx.WithUser(r.Context(), types.UserDto{
+				Id:        claims.Uid,
+				UType:     claims.UType,
+				SessionId: claims.Sid,
 			})
+			ctx = reqctx.WithLogger(ctx, reqctx.Logger(ctx).With("user_id", claims.Uid))
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -75,12 +111,92 @@ func AuthMiddleware(authService types.AuthService) func(http.Handler) http.Handl
 
 func RequireUser(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		user := r.Context().Value("user").(types.UserDto)
-		if user.Id == 0 {
-			// utils.WriteJsonError(w, http.StatusForbidden, fmt.Errorf("not authorized"))
-			http.Redirect(w, r, "/login", http.StatusFound)
+		user, ok := reqctx.UserFromContext(r.Context())
+		if !ok || user.Id == 0 {
+			utils.WriteProblem(w, utils.NewProblem(http.StatusUnauthorized, utils.CodeUnauthorized, "not authenticated"))
 			return
 		}
 		next.ServeHTTP(w, r)
 	})
 }
+
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := reqctx.RoleFromContext(r.Context())
+			if !ok {
+				utils.WriteJsonError(w, http.StatusUnauthorized, fmt.Errorf("not authorized"))
+				return
+			}
+			if !slices.Contains(roles, role) {
+				utils.WriteJsonError(w, http.StatusForbidden, fmt.Errorf("not authorized"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DbTimeout bounds the request context, and with it every store call made
+// with r.Context(), so a slow query can't hold a connection forever.
+func DbTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// MaxBodyBytes caps every request body at n bytes and makes n the limit of
+// utils.ParseJson. Handlers may still apply a smaller limit of their own.
+func MaxBodyBytes(n int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, n)
+			}
+			next.ServeHTTP(w, r.WithContext(reqctx.WithMaxBodyBytes(r.Context(), n)))
+		})
+	}
+}
+
+// RequestId tags every request with an id, reusing the caller's X-Request-Id
+// when it looks sane so requests can be traced across services. The request's
+// logger carries the id on every line.
+func RequestId(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get("X-Request-Id")
+		if requestId == "" || len(requestId) > 64 {
+			requestId = newRequestId()
+		}
+
+		w.Header().Set("X-Request-Id", requestId)
+		ctx := reqctx.WithRequestId(r.Context(), requestId)
+		ctx = reqctx.WithLogger(ctx, reqctx.Logger(ctx).With("request_id", requestId))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Tenant records the host the request was addressed to as its tenant, and the
+// request's logger carries it on every line.
+func Tenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant := r.Host
+		if host, _, err := net.SplitHostPort(tenant); err == nil {
+			tenant = host
+		}
+		tenant = strings.ToLower(tenant)
+
+		ctx := reqctx.WithTenant(r.Context(), tenant)
+		ctx = reqctx.WithLogger(ctx, reqctx.Logger(ctx).With("tenant", tenant))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,274 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/reqctx"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/auth"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRequireUser(t *testing.T) {
+	handler := RequireUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("should return unauthorized instead of panicking without auth middleware", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/user", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("expected status code %d, got %d", http.StatusUnauthorized, rr.Code)
+		}
+	})
+
+	t.Run("should return unauthorized for a user without an id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/user", nil)
+		req = req.WithContext(reqctx.WithUser(req.Context(), types.UserDto{}))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var problem utils.Problem
+		if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+			t.Fatal(err)
+		}
+		if rr.Code != http.StatusUnauthorized || problem.Code != utils.CodeUnauthorized {
+			t.Errorf("expected %d %s, got %d %s", http.StatusUnauthorized, utils.CodeUnauthorized, rr.Code, problem.Code)
+		}
+	})
+
+	t.Run("should pass through an authenticated user", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/user", nil)
+		req = req.WithContext(reqctx.WithUser(req.Context(), types.UserDto{Id: 1}))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected status code %d, got %d", http.StatusOK, rr.Code)
+		}
+	})
+}
+
+func TestAuthMiddlewareSessions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	cfg := config.Default()
+	if err := cfg.Auth.GenerateKeys(); err != nil {
+		t.Fatal(err)
+	}
+	authService := auth.NewAuthService(auth.NewMemoryStore(nil), cfg.Auth)
+	handler := AuthMiddleware(authService, cfg.Cookie)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := reqctx.MustUser(r.Context())
+		json.NewEncoder(w).Encode(map[string]int{"sid": user.SessionId})
+	}))
+
+	sid, err := authService.StartSession(ctx, 1, "Firefox", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	do := func(cookie string, claims types.CustomClaims) *httptest.ResponseRecorder {
+		t.Helper()
+		token, err := authService.SignJwt(time.Minute, claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/user", nil)
+		req.AddCookie(&http.Cookie{Name: cookie, Value: token})
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for _, cookie := range []string{"ACCESS_TOKEN", "REFRESH_TOKEN"} {
+		rr := do(cookie, types.CustomClaims{Uid: 1, Sid: sid})
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"sid":`+strconv.Itoa(sid)) {
+			t.Errorf("%s: expected the session to pass, got %d: %s", cookie, rr.Code, rr.Body)
+		}
+	}
+
+	// tokens refreshed from the refresh token stay in the session
+	rr := do("REFRESH_TOKEN", types.CustomClaims{Uid: 1, Sid: sid})
+	var refreshed *http.Cookie
+	for _, c := range rr.Result().Cookies() {
+		if c.Name == "ACCESS_TOKEN" {
+			refreshed = c
+		}
+	}
+	if refreshed == nil {
+		t.Fatal("expected a refreshed access token")
+	}
+	token, err := authService.VerifyToken(refreshed.Value)
+	if err != nil || token.Claims.(*types.CustomClaims).Sid != sid {
+		t.Errorf("expected the refreshed token to carry session %d, got %+v (%v)", sid, token, err)
+	}
+
+	if rr := do("ACCESS_TOKEN", types.CustomClaims{Uid: 1}); rr.Code != http.StatusFound {
+		t.Errorf("expected a token without session to be refused, got %d", rr.Code)
+	}
+
+	if err := authService.RevokeSession(ctx, 1, sid); err != nil {
+		t.Fatal(err)
+	}
+	for _, cookie := range []string{"ACCESS_TOKEN", "REFRESH_TOKEN"} {
+		rr := do(cookie, types.CustomClaims{Uid: 1, Sid: sid})
+		if rr.Code != http.StatusFound {
+			t.Errorf("%s: expected a revoked session to be refused, got %d", cookie, rr.Code)
+		}
+		for _, c := range rr.Result().Cookies() {
+			if c.Value != "" {
+				t.Errorf("%s: expected cookies to be cleared, got %s=%s", cookie, c.Name, c.Value)
+			}
+		}
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	handler := RequireRole(types.UTypeAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	cases := []struct {
+		name   string
+		user   *types.UserDto
+		status int
+	}{
+		{"should return unauthorized without auth middleware", nil, http.StatusUnauthorized},
+		{"should forbid other roles", &types.UserDto{Id: 1, UType: types.UTypeStudent}, http.StatusForbidden},
+		{"should allow the required role", &types.UserDto{Id: 1, UType: types.UTypeAdmin}, http.StatusOK},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if c.user != nil {
+				req = req.WithContext(reqctx.WithUser(req.Context(), *c.user))
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != c.status {
+				t.Errorf("expected status code %d, got %d", c.status, rr.Code)
+			}
+		})
+	}
+}
+
+func TestRequestId(t *testing.T) {
+	var got string
+	handler := RequestId(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = reqctx.RequestIdFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-Id", "abc-123")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if got != "abc-123" || rr.Header().Get("X-Request-Id") != "abc-123" {
+		t.Errorf("expected incoming request id to be reused, got %q", got)
+	}
+}
+
+func TestTenant(t *testing.T) {
+	var got string
+	handler := Tenant(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = reqctx.TenantFromContext(r.Context())
+	}))
+
+	for host, want := range map[string]string{"Placements.College.edu:8090": "placements.college.edu", "college.edu": "college.edu"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = host
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if got != want {
+			t.Errorf("%s: expected tenant %q, got %q", host, want, got)
+		}
+	}
+}
+
+func TestDbTimeout(t *testing.T) {
+	var hasDeadline bool
+	handler := DbTimeout(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, hasDeadline = r.Context().Deadline()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !hasDeadline {
+		t.Error("expected request context to have a deadline")
+	}
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	var payload struct {
+		Data string `json:"data"`
+	}
+	handler := func(n int64) http.Handler {
+		return MaxBodyBytes(n)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := utils.ParseJson(r, &payload); err != nil {
+				utils.WriteJsonError(w, http.StatusBadRequest, err)
+			}
+		}))
+	}
+	do := func(n int64, size int) int {
+		body := `{"data":"` + strings.Repeat("a", size) + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		handler(n).ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// a raised limit lets bodies past the default through to ParseJson
+	if code := do(4<<20, 2<<20); code != http.StatusOK {
+		t.Errorf("expected a 2 MiB body to be accepted under a 4 MiB limit, got %d", code)
+	}
+	if code := do(1<<10, 2<<10); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected a 2 KiB body to be refused under a 1 KiB limit, got %d", code)
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer slog.SetDefault(prev)
+
+	router := chi.NewRouter()
+	router.Use(RequestId, AccessLog)
+	router.Get("/calendar/feed/{token}.ics", func(w http.ResponseWriter, r *http.Request) {
+		// stands in for AuthMiddleware having identified the caller
+		if requestLog, ok := reqctx.RequestLogFromContext(r.Context()); ok {
+			requestLog.UserId = 7
+		}
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/calendar/feed/secret.ics", nil)
+	req.Header.Set("X-Request-Id", "abc-123")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a json access log line, got %q", buf.String())
+	}
+	if record["request_id"] != "abc-123" || record["user_id"] != float64(7) || record["status"] != float64(http.StatusTeapot) {
+		t.Errorf("expected request id, user id and status on access log, got %v", record)
+	}
+	if record["route"] != "/calendar/feed/{token}.ics" || strings.Contains(buf.String(), "secret") {
+		t.Errorf("expected route pattern without the raw path, got %v", record)
+	}
+}
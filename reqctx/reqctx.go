@@ -0,0 +1,118 @@
+package reqctx
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// ctxKey is unexported so no other package can read or overwrite these values
+// by accident.
+type ctxKey int
+
+const (
+	userKey ctxKey = iota
+	requestIdKey
+	tenantKey
+	auditEventKey
+	loggerKey
+	requestLogKey
+	maxBodyBytesKey
+)
+
+// RequestLog collects access log fields that only become known further down
+// the middleware chain.
+type RequestLog struct {
+	UserId int
+}
+
+func WithUser(ctx context.Context, user types.UserDto) context.Context {
+	return context.WithValue(ctx, userKey, user)
+}
+
+// UserFromContext returns the authenticated user, ok is false if no
+// authentication middleware ran for the request.
+func UserFromContext(ctx context.Context) (types.UserDto, bool) {
+	user, ok := ctx.Value(userKey).(types.UserDto)
+	return user, ok
+}
+
+// MustUser is UserFromContext for code that only runs behind RequireUser. It
+// panics if there is no user.
+func MustUser(ctx context.Context) types.UserDto {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		panic("reqctx: no user in context")
+	}
+	return user
+}
+
+func RoleFromContext(ctx context.Context) (string, bool) {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	return user.UType, true
+}
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey, requestId)
+}
+
+func RequestIdFromContext(ctx context.Context) (string, bool) {
+	requestId, ok := ctx.Value(requestIdKey).(string)
+	return requestId, ok
+}
+
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+// TenantFromContext returns the host the request was addressed to, which names
+// the college when one deployment serves several.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey).(string)
+	return tenant, ok
+}
+
+func WithAuditEvent(ctx context.Context, event *types.AuditEvent) context.Context {
+	return context.WithValue(ctx, auditEventKey, event)
+}
+
+func AuditEventFromContext(ctx context.Context) (*types.AuditEvent, bool) {
+	event, ok := ctx.Value(auditEventKey).(*types.AuditEvent)
+	return event, ok
+}
+
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// Logger returns the request scoped logger, or the default logger outside of
+// a request.
+func Logger(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+func WithRequestLog(ctx context.Context, requestLog *RequestLog) context.Context {
+	return context.WithValue(ctx, requestLogKey, requestLog)
+}
+
+func RequestLogFromContext(ctx context.Context) (*RequestLog, bool) {
+	requestLog, ok := ctx.Value(requestLogKey).(*RequestLog)
+	return requestLog, ok
+}
+
+func WithMaxBodyBytes(ctx context.Context, n int64) context.Context {
+	return context.WithValue(ctx, maxBodyBytesKey, n)
+}
+
+// MaxBodyBytes returns the body limit set by the MaxBodyBytes middleware.
+func MaxBodyBytes(ctx context.Context) (int64, bool) {
+	n, ok := ctx.Value(maxBodyBytesKey).(int64)
+	return n, ok
+}
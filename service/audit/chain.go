@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// GenesisHash is the previous hash of the first event in the chain.
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// ComputeHash hashes an event together with the hash of the event before it,
+// so that changing or removing any row breaks every hash after it.
+func ComputeHash(prevHash string, e types.AuditEvent) string {
+	// a struct keeps the field order, and json escaping keeps values from
+	// bleeding into each other
+	b, _ := json.Marshal(struct {
+		PrevHash   string `json:"prevHash"`
+		ActorId    int    `json:"actorId"`
+		ActorRole  string `json:"actorRole"`
+		Action     string `json:"action"`
+		EntityType string `json:"entityType"`
+		EntityId   string `json:"entityId"`
+		Status     int    `json:"status"`
+		Changes    string `json:"changes"`
+		Ip         string `json:"ip"`
+		UserAgent  string `json:"userAgent"`
+		CreatedAt  string `json:"createdAt"`
+	}{
+		PrevHash:   prevHash,
+		ActorId:    e.ActorId,
+		ActorRole:  e.ActorRole,
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityId:   e.EntityId,
+		Status:     e.Status,
+		Changes:    string(e.Changes),
+		Ip:         e.Ip,
+		UserAgent:  e.UserAgent,
+		CreatedAt:  e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyChain checks events ordered by id, continuing from prevHash. It
+// returns the id of the first event that doesn't match, or 0.
+func VerifyChain(prevHash string, events []types.AuditEvent) int64 {
+	for _, e := range events {
+		if e.PrevHash != prevHash || ComputeHash(prevHash, e) != e.Hash {
+			return e.Id
+		}
+		prevHash = e.Hash
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+func TestVerifyChain(t *testing.T) {
+	events := buildChain([]types.AuditEvent{
+		{Id: 1, ActorId: 1, ActorRole: types.UTypeAdmin, Action: "POST /register", Status: 201},
+		{Id: 2, ActorId: 1, ActorRole: types.UTypeAdmin, Action: "GET /admin/audit-events", Status: 200},
+		{Id: 3, ActorId: 2, ActorRole: types.UTypeStudent, Action: "POST /logout", Status: 202},
+	})
+
+	t.Run("should accept an intact chain", func(t *testing.T) {
+		if brokenId := VerifyChain(GenesisHash, events); brokenId != 0 {
+			t.Errorf("expected chain to be intact, broken at %d", brokenId)
+		}
+	})
+
+	t.Run("should detect a modified event", func(t *testing.T) {
+		tampered := append([]types.AuditEvent{}, events...)
+		tampered[1].ActorId = 3
+
+		if brokenId := VerifyChain(GenesisHash, tampered); brokenId != 2 {
+			t.Errorf("expected chain to be broken at 2, got %d", brokenId)
+		}
+	})
+
+	t.Run("should detect a removed event", func(t *testing.T) {
+		tampered := []types.AuditEvent{events[0], events[2]}
+
+		if brokenId := VerifyChain(GenesisHash, tampered); brokenId != 3 {
+			t.Errorf("expected chain to be broken at 3, got %d", brokenId)
+		}
+	})
+}
+
+func buildChain(events []types.AuditEvent) []types.AuditEvent {
+	prevHash := GenesisHash
+	createdAt := time.Date(2024, 9, 11, 18, 4, 26, 0, time.UTC)
+	for i := range events {
+		events[i].CreatedAt = createdAt.Add(time.Duration(i) * time.Minute)
+		events[i].PrevHash = prevHash
+		events[i].Hash = ComputeHash(prevHash, events[i])
+		prevHash = events[i].Hash
+	}
+	return events
+}
@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// MemoryStore is an in-memory types.AuditStore for tests and demo mode. Events
+// are chained exactly like Store does.
+type MemoryStore struct {
+	mu     sync.Mutex
+	events []types.AuditEvent
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) AppendAuditEvent(ctx context.Context, e types.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prevHash := GenesisHash
+	if len(s.events) > 0 {
+		prevHash = s.events[len(s.events)-1].Hash
+	}
+
+	e.Id = int64(len(s.events) + 1)
+	e.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	e.PrevHash = prevHash
+	e.Hash = ComputeHash(prevHash, e)
+
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *MemoryStore) ListAuditEvents(ctx context.Context, filter types.AuditEventFilter) ([]types.AuditEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := []types.AuditEvent{}
+	skipped := 0
+	for i := len(s.events) - 1; i >= 0; i-- {
+		e := s.events[i]
+		if filter.ActorId != 0 && e.ActorId != filter.ActorId ||
+			filter.Action != "" && e.Action != filter.Action ||
+			filter.EntityType != "" && e.EntityType != filter.EntityType ||
+			filter.EntityId != "" && e.EntityId != filter.EntityId ||
+			!filter.From.IsZero() && e.CreatedAt.Before(filter.From) ||
+			!filter.To.IsZero() && !e.CreatedAt.Before(filter.To) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		if filter.Limit > 0 && len(events) == filter.Limit {
+			break
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func (s *MemoryStore) EachAuditEvent(ctx context.Context, filter types.AuditEventFilter, fn func(e types.AuditEvent) error) error {
+	events, err := s.ListAuditEvents(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) VerifyAuditChain(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return VerifyChain(GenesisHash, s.events), nil
+}
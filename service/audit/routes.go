@@ -0,0 +1,232 @@
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/middlewares"
+	"github.com/SufyaanKhateeb/college-placement-app-api/reqctx"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+type Handler struct {
+	Store         types.AuditStore
+	AuthService   types.AuthService
+	Cookie        config.CookieConfig
+	ExportTimeout time.Duration
+}
+
+func NewHandler(s types.AuditStore, authService types.AuthService, cookie config.CookieConfig, exportTimeout time.Duration) *Handler {
+	return &Handler{
+		Store:         s,
+		AuthService:   authService,
+		Cookie:        cookie,
+		ExportTimeout: exportTimeout,
+	}
+}
+
+func (h *Handler) RegisterRoutes(r *chi.Mux) {
+	// Admin Routes
+	r.Group(func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware(h.AuthService, h.Cookie), middlewares.RequireUser, middlewares.RequireRole(types.UTypeAdmin))
+		r.Get("/admin/audit-events", h.handleList)
+		r.Get("/admin/audit-events/export", h.handleExport)
+		r.Get("/admin/audit-events/verify", h.handleVerify)
+	})
+}
+
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r.URL.Query())
+	if err != nil {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInvalidQuery, err.Error()))
+		return
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
+	}
+
+	events, err := h.Store.ListAuditEvents(r.Context(), filter)
+	if err != nil {
+		utils.WriteInternalError(w, r, "listing audit events failed", err)
+		return
+	}
+
+	utils.WriteJson(w, http.StatusOK, events)
+}
+
+func (h *Handler) handleExport(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r.URL.Query())
+	if err != nil {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInvalidQuery, err.Error()))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInvalidQuery, fmt.Sprintf("unsupported export format %s", format)))
+		return
+	}
+
+	// a large export outlives the query timeout set by DbTimeout and the
+	// server's write timeout, both are swapped for ExportTimeout. A client
+	// that goes away fails the next write and stops the export.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), h.ExportTimeout)
+	defer cancel()
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(h.ExportTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		utils.WriteInternalError(w, r, "extending export write deadline failed", err)
+		return
+	}
+
+	filename := "audit-events-" + time.Now().UTC().Format("20060102T150405Z") + "." + format
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	// events are streamed from the store, a failure half way can only cut
+	// the download short
+	var write func(e types.AuditEvent) error
+	var finish func() error
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		sep := "["
+		write = func(e types.AuditEvent) error {
+			if _, err := io.WriteString(w, sep); err != nil {
+				return err
+			}
+			sep = ","
+			return enc.Encode(e)
+		}
+		finish = func() error {
+			if sep == "[" {
+				_, err := io.WriteString(w, "[]\n")
+				return err
+			}
+			_, err := io.WriteString(w, "]\n")
+			return err
+		}
+	} else {
+		w.Header().Set("Content-Type", "text/csv")
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"id", "createdAt", "actorId", "actorRole", "action", "entityType", "entityId", "status", "changes", "ip", "userAgent", "prevHash", "hash"})
+		write = func(e types.AuditEvent) error {
+			return cw.Write([]string{
+				strconv.FormatInt(e.Id, 10),
+				e.CreatedAt.UTC().Format(time.RFC3339Nano),
+				strconv.Itoa(e.ActorId),
+				csvText(e.ActorRole),
+				csvText(e.Action),
+				csvText(e.EntityType),
+				csvText(e.EntityId),
+				strconv.Itoa(e.Status),
+				csvText(string(e.Changes)),
+				csvText(e.Ip),
+				csvText(e.UserAgent),
+				e.PrevHash,
+				e.Hash,
+			})
+		}
+		finish = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	}
+
+	written := 0
+	err = h.Store.EachAuditEvent(ctx, filter, func(e types.AuditEvent) error {
+		written++
+		return write(e)
+	})
+	if err != nil && written == 0 {
+		// nothing was sent yet, the store failed outright
+		w.Header().Del("Content-Disposition")
+		utils.WriteInternalError(w, r, "audit export failed", err)
+		return
+	}
+	if err == nil {
+		err = finish()
+	}
+	if err != nil {
+		reqctx.Logger(r.Context()).Error("audit export failed", "err", err, "written", written)
+	}
+}
+
+// csvText keeps spreadsheets from running cells that users control, such as
+// the user agent, as formulas.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func (h *Handler) handleVerify(w http.ResponseWriter, r *http.Request) {
+	brokenId, err := h.Store.VerifyAuditChain(r.Context())
+	if err != nil {
+		utils.WriteInternalError(w, r, "verifying audit chain failed", err)
+		return
+	}
+
+	utils.WriteJson(w, http.StatusOK, map[string]any{
+		"intact":        brokenId == 0,
+		"firstBrokenId": brokenId,
+	})
+}
+
+func parseFilter(q url.Values) (types.AuditEventFilter, error) {
+	var filter types.AuditEventFilter
+	var err error
+
+	filter.Action = q.Get("action")
+	filter.EntityType = q.Get("entityType")
+	filter.EntityId = q.Get("entityId")
+
+	if v := q.Get("actorId"); v != "" {
+		if filter.ActorId, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("invalid actorId %s", v)
+		}
+	}
+	if v := q.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, fmt.Errorf("invalid from %s, expected RFC 3339 time", v)
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, fmt.Errorf("invalid to %s, expected RFC 3339 time", v)
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("invalid limit %s", v)
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("invalid offset %s", v)
+		}
+	}
+
+	return filter, nil
+}
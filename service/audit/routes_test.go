@@ -0,0 +1,116 @@
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
+)
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+	store := NewMemoryStore()
+	for _, e := range []types.AuditEvent{
+		{ActorId: 1, Action: "POST /api/v1/login", EntityType: "user", EntityId: "1", Status: 200, UserAgent: "=HYPERLINK(\"http://evil.example\")"},
+		{ActorId: 2, Action: "POST /api/v1/register", Status: 201, UserAgent: "-cmd", Ip: "10.0.0.1", Changes: []byte(`{"a":1}`)},
+	} {
+		if err := store.AppendAuditEvent(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handler := NewHandler(store, nil, config.Default().Cookie, time.Minute)
+
+	export := func(format string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.handleExport(rr, httptest.NewRequest(http.MethodGet, "/admin/audit-events/export?format="+format, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+		}
+		return rr
+	}
+
+	t.Run("csv escapes formulas", func(t *testing.T) {
+		records, err := csv.NewReader(export("csv").Body).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 3 {
+			t.Fatalf("expected a header and 2 rows, got %d", len(records))
+		}
+		// newest first, userAgent is the 11th column
+		if got := records[1][10]; got != "'-cmd" {
+			t.Errorf("expected the user agent to be escaped, got %q", got)
+		}
+		if got := records[2][10]; got != "'=HYPERLINK(\"http://evil.example\")" {
+			t.Errorf("expected the user agent to be escaped, got %q", got)
+		}
+		if got := records[1][9]; got != "10.0.0.1" {
+			t.Errorf("expected plain cells to be kept, got %q", got)
+		}
+	})
+
+	t.Run("json is a single array", func(t *testing.T) {
+		var events []types.AuditEvent
+		if err := json.NewDecoder(export("json").Body).Decode(&events); err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 2 || events[1].UserAgent != "=HYPERLINK(\"http://evil.example\")" {
+			t.Errorf("expected both events unchanged, got %+v", events)
+		}
+	})
+
+	t.Run("json without events", func(t *testing.T) {
+		var events []types.AuditEvent
+		rr := httptest.NewRecorder()
+		handler.handleExport(rr, httptest.NewRequest(http.MethodGet, "/admin/audit-events/export?format=json&actorId=99", nil))
+		if err := json.NewDecoder(rr.Body).Decode(&events); err != nil || events == nil || len(events) != 0 {
+			t.Errorf("expected an empty array, got %v (%v)", events, err)
+		}
+	})
+
+	t.Run("outlives the request timeout", func(t *testing.T) {
+		handler := NewHandler(deadlineStore{store}, nil, config.Default().Cookie, time.Minute)
+		// the request's own deadline, set by DbTimeout, has already passed
+		ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+		rr := httptest.NewRecorder()
+		handler.handleExport(rr, httptest.NewRequest(http.MethodGet, "/admin/audit-events/export?format=json", nil).WithContext(ctx))
+		var events []types.AuditEvent
+		if err := json.NewDecoder(rr.Body).Decode(&events); err != nil || len(events) != 2 {
+			t.Errorf("expected both events, got %d %s (%v)", rr.Code, rr.Body, err)
+		}
+	})
+
+	t.Run("invalid queries", func(t *testing.T) {
+		for _, q := range []string{"format=xml", "actorId=abc", "from=yesterday"} {
+			rr := httptest.NewRecorder()
+			handler.handleExport(rr, httptest.NewRequest(http.MethodGet, "/admin/audit-events/export?"+q, nil))
+			var problem utils.Problem
+			if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+			if rr.Code != http.StatusBadRequest || problem.Code != utils.CodeInvalidQuery {
+				t.Errorf("%s: expected %d %s, got %d %s", q, http.StatusBadRequest, utils.CodeInvalidQuery, rr.Code, problem.Code)
+			}
+		}
+	})
+}
+
+// deadlineStore fails exports whose context is done, as a database would.
+type deadlineStore struct {
+	*MemoryStore
+}
+
+func (s deadlineStore) EachAuditEvent(ctx context.Context, filter types.AuditEventFilter, fn func(e types.AuditEvent) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.MemoryStore.EachAuditEvent(ctx, filter, fn)
+}
@@ -0,0 +1,187 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/db"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/jackc/pgx/v5"
+)
+
+// chainLockId is the advisory lock serializing appends, each event needs the
+// hash of the one inserted right before it
+const chainLockId = 2024091118
+
+const auditEventColumns = "id, actorId, actorRole, action, entityType, entityId, status, changes, ip, userAgent, createdAt, prevHash, hash"
+
+type Store struct {
+	db db.Querier
+}
+
+func NewStore(db db.Querier) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+func (s *Store) AppendAuditEvent(ctx context.Context, e types.AuditEvent) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "select pg_advisory_xact_lock($1)", chainLockId); err != nil {
+		return err
+	}
+
+	prevHash := GenesisHash
+	err = tx.QueryRow(ctx, "select hash from audit_events order by id desc limit 1").Scan(&prevHash)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	// postgres keeps microseconds, hash what will be read back
+	e.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	e.PrevHash = prevHash
+	e.Hash = ComputeHash(prevHash, e)
+
+	_, err = tx.Exec(ctx, `
+		insert into audit_events (actorId, actorRole, action, entityType, entityId, status, changes, ip, userAgent, createdAt, prevHash, hash)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		e.ActorId, e.ActorRole, e.Action, e.EntityType, e.EntityId, e.Status, string(e.Changes), e.Ip, e.UserAgent, e.CreatedAt, e.PrevHash, e.Hash,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *Store) ListAuditEvents(ctx context.Context, filter types.AuditEventFilter) ([]types.AuditEvent, error) {
+	events := []types.AuditEvent{}
+	err := s.EachAuditEvent(ctx, filter, func(e types.AuditEvent) error {
+		events = append(events, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (s *Store) EachAuditEvent(ctx context.Context, filter types.AuditEventFilter, fn func(e types.AuditEvent) error) error {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.ActorId != 0 {
+		add("actorId = $%d", filter.ActorId)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.EntityType != "" {
+		add("entityType = $%d", filter.EntityType)
+	}
+	if filter.EntityId != "" {
+		add("entityId = $%d", filter.EntityId)
+	}
+	if !filter.From.IsZero() {
+		add("createdAt >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("createdAt < $%d", filter.To)
+	}
+
+	query := "select " + auditEventColumns + " from audit_events"
+	if len(conds) > 0 {
+		query += " where " + strings.Join(conds, " and ")
+	}
+	query += " order by id desc"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" limit $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" offset $%d", len(args))
+	}
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanRowIntoAuditEvent(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(*e); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (s *Store) VerifyAuditChain(ctx context.Context) (int64, error) {
+	rows, err := s.db.Query(ctx, "select "+auditEventColumns+" from audit_events order by id")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	prevHash := GenesisHash
+	for rows.Next() {
+		e, err := scanRowIntoAuditEvent(rows)
+		if err != nil {
+			return 0, err
+		}
+		if brokenId := VerifyChain(prevHash, []types.AuditEvent{*e}); brokenId != 0 {
+			return brokenId, nil
+		}
+		prevHash = e.Hash
+	}
+
+	return 0, rows.Err()
+}
+
+func scanRowIntoAuditEvent(rows pgx.Rows) (*types.AuditEvent, error) {
+	e := new(types.AuditEvent)
+	var changes string
+
+	err := rows.Scan(
+		&e.Id,
+		&e.ActorId,
+		&e.ActorRole,
+		&e.Action,
+		&e.EntityType,
+		&e.EntityId,
+		&e.Status,
+		&changes,
+		&e.Ip,
+		&e.UserAgent,
+		&e.CreatedAt,
+		&e.PrevHash,
+		&e.Hash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes != "" {
+		e.Changes = json.RawMessage(changes)
+	}
+	return e, nil
+}
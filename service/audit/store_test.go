@@ -0,0 +1,25 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/storetest"
+	"github.com/SufyaanKhateeb/college-placement-app-api/testdb"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+func TestMain(m *testing.M) {
+	testdb.Main(m)
+}
+
+func TestStore(t *testing.T) {
+	storetest.AuditStore(t, func(t *testing.T) types.AuditStore {
+		return NewStore(testdb.New(t))
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	storetest.AuditStore(t, func(t *testing.T) types.AuditStore {
+		return NewMemoryStore()
+	})
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// MemoryStore is an in-memory types.AuthStore for tests and demo mode. It
+// passes the same conformance suite as AuthStore.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[int]types.Session
+	nextId   int
+	// users stands in for the join on users in AuthStore.GetSession, nil
+	// treats every user as active for tests that have no user store
+	users types.UserStore
+}
+
+func NewMemoryStore(users types.UserStore) *MemoryStore {
+	return &MemoryStore{
+		sessions: map[int]types.Session{},
+		nextId:   1,
+		users:    users,
+	}
+}
+
+func (s *MemoryStore) CreateSession(ctx context.Context, session types.Session) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	session.Id = s.nextId
+	session.CreatedAt = now
+	session.LastSeenAt = now
+	session.RevokedAt = nil
+	s.nextId++
+
+	s.sessions[session.Id] = session
+	return session.Id, nil
+}
+
+func (s *MemoryStore) GetSession(ctx context.Context, id int) (*types.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	if s.users != nil {
+		// deleted users are not found either
+		u, err := s.users.GetUserById(ctx, session.UserId)
+		if err != nil {
+			return nil, err
+		}
+		if u.DisabledAt != nil {
+			return nil, types.ErrNotFound
+		}
+	}
+	return &session, nil
+}
+
+func (s *MemoryStore) ListSessions(ctx context.Context, userId int, since time.Time) ([]types.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := []types.Session{}
+	for _, session := range s.sessions {
+		if session.UserId == userId && session.RevokedAt == nil && session.CreatedAt.After(since) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Id > sessions[j].Id
+	})
+	return sessions, nil
+}
+
+func (s *MemoryStore) TouchSession(ctx context.Context, id int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return types.ErrNotFound
+	}
+	session.LastSeenAt = at.UTC().Truncate(time.Microsecond)
+	s.sessions[id] = session
+	return nil
+}
+
+func (s *MemoryStore) RevokeSession(ctx context.Context, userId, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || session.UserId != userId || session.RevokedAt != nil {
+		return types.ErrNotFound
+	}
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	session.RevokedAt = &now
+	s.sessions[id] = session
+	return nil
+}
+
+func (s *MemoryStore) RevokeSessions(ctx context.Context, userId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	for id, session := range s.sessions {
+		if session.UserId == userId && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			s.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+// Snapshot returns a function that puts the store back as it is now, memtx
+// rolls back failed units of work with it.
+func (s *MemoryStore) Snapshot() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, nextId := maps.Clone(s.sessions), s.nextId
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.sessions, s.nextId = sessions, nextId
+	}
+}
@@ -1,8 +1,16 @@
 package auth
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"context"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/tracing"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func HashPassword(ctx context.Context, password string) (string, error) {
+	_, span := tracing.Tracer().Start(ctx, "bcrypt.hash")
+	defer span.End()
 
-func HashPassword(password string) (string, error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 
 	if err != nil {
@@ -12,6 +20,9 @@ func HashPassword(password string) (string, error) {
 	return string(hash), nil
 }
 
-func CompareHashAndPassword(password string, hash string) error {
+func CompareHashAndPassword(ctx context.Context, password string, hash string) error {
+	_, span := tracing.Tracer().Start(ctx, "bcrypt.compare")
+	defer span.End()
+
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
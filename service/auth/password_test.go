@@ -1,10 +1,14 @@
 package auth
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestHashPassword(t *testing.T) {
+	t.Parallel()
 	originalPass := "password"
-	hash, err := HashPassword(originalPass)
+	hash, err := HashPassword(context.Background(), originalPass)
 	if err != nil {
 		t.Errorf("error hashing password: %v", err)
 	}
@@ -19,17 +23,18 @@ func TestHashPassword(t *testing.T) {
 }
 
 func TestCompareHashAndPassword(t *testing.T) {
+	t.Parallel()
 	originalPass := "password"
-	hash, err := HashPassword(originalPass)
+	hash, err := HashPassword(context.Background(), originalPass)
 	if err != nil {
 		t.Errorf("error hashing password: %v", err)
 	}
 
-	if err := CompareHashAndPassword(originalPass, hash); err != nil {
+	if err := CompareHashAndPassword(context.Background(), originalPass, hash); err != nil {
 		t.Error("expected password to match hash")
 	}
 
-	if err := CompareHashAndPassword("wrongPass", hash); err == nil {
+	if err := CompareHashAndPassword(context.Background(), "wrongPass", hash); err == nil {
 		t.Error("expected hash to be different from password")
 	}
 }
@@ -1,7 +1,12 @@
 package auth
 
 import (
+	"context"
+	"crypto/rsa"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/SufyaanKhateeb/college-placement-app-api/config"
@@ -10,15 +15,31 @@ import (
 )
 
 type AuthService struct {
-	Store types.AuthStore
+	Store           types.AuthStore
+	privateKey      *rsa.PrivateKey
+	publicKey       *rsa.PublicKey
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
 }
 
-func NewAuthService(store types.AuthStore) *AuthService {
+func NewAuthService(store types.AuthStore, cfg config.AuthConfig) *AuthService {
 	return &AuthService{
-		Store: store,
+		Store:           store,
+		privateKey:      cfg.PrivateKey,
+		publicKey:       cfg.PublicKey,
+		accessTokenTTL:  cfg.AccessTokenTTL,
+		refreshTokenTTL: cfg.RefreshTokenTTL,
 	}
 }
 
+func (a *AuthService) AccessTokenTTL() time.Duration {
+	return a.accessTokenTTL
+}
+
+func (a *AuthService) RefreshTokenTTL() time.Duration {
+	return a.refreshTokenTTL
+}
+
 const Issuer = "placement-app-server"
 const Audience = "placement-app-client"
 
@@ -29,7 +50,7 @@ func (a *AuthService) SignJwt(expirationTime time.Duration, claims types.CustomC
 	claims.Audience = jwt.ClaimStrings{Audience}
 
 	tkn := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	return tkn.SignedString(config.Env.PrivateKey)
+	return tkn.SignedString(a.privateKey)
 }
 
 func (a *AuthService) VerifyToken(tkn string) (*jwt.Token, error) {
@@ -39,8 +60,135 @@ func (a *AuthService) VerifyToken(tkn string) (*jwt.Token, error) {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 
-		return config.Env.PublicKey, nil
+		return a.publicKey, nil
 	}, jwt.WithIssuer(Issuer), jwt.WithAudience(Audience))
 
 	return token, err
 }
+
+// InviteAudience keeps invite tokens from being accepted as access tokens and
+// the other way round.
+const InviteAudience = "placement-app-invite"
+
+// SignInvite signs a token naming the invite. The expiry is part of the
+// token, so renewing an invite invalidates links issued before.
+func (a *AuthService) SignInvite(inviteId int, expiresAt time.Time) (string, error) {
+	claims := jwt.RegisteredClaims{
+		Subject:   strconv.Itoa(inviteId),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		Issuer:    Issuer,
+		Audience:  jwt.ClaimStrings{InviteAudience},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(a.privateKey)
+}
+
+func (a *AuthService) VerifyInvite(tkn string) (int, time.Time, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(tkn, claims, func(token *jwt.Token) (interface{}, error) {
+		return a.publicKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}), jwt.WithIssuer(Issuer), jwt.WithAudience(InviteAudience), jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid invite id %q", claims.Subject)
+	}
+	return id, claims.ExpiresAt.Time, nil
+}
+
+// PasswordResetAudience keeps reset tokens from being accepted as access or
+// invite tokens.
+const PasswordResetAudience = "placement-app-password-reset"
+
+// SignPasswordReset signs a token naming the user and the reset it belongs to,
+// so it stops working once the password is set or the user is reset again.
+func (a *AuthService) SignPasswordReset(userId int, resetAt, expiresAt time.Time) (string, error) {
+	claims := jwt.RegisteredClaims{
+		Subject:   strconv.Itoa(userId),
+		ID:        strconv.FormatInt(resetAt.UnixMicro(), 10),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		Issuer:    Issuer,
+		Audience:  jwt.ClaimStrings{PasswordResetAudience},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(a.privateKey)
+}
+
+func (a *AuthService) VerifyPasswordReset(tkn string) (int, time.Time, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(tkn, claims, func(token *jwt.Token) (interface{}, error) {
+		return a.publicKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}), jwt.WithIssuer(Issuer), jwt.WithAudience(PasswordResetAudience), jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid user id %q", claims.Subject)
+	}
+	resetAt, err := strconv.ParseInt(claims.ID, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid reset %q", claims.ID)
+	}
+	return id, time.UnixMicro(resetAt).UTC(), nil
+}
+
+const (
+	// sessionTouchInterval limits last seen updates to one write per session
+	// and minute, rather than one per request
+	sessionTouchInterval = time.Minute
+	maxUserAgentLength   = 512
+)
+
+func (a *AuthService) StartSession(ctx context.Context, userId int, userAgent, ip string) (int, error) {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+	}
+	return a.Store.CreateSession(ctx, types.Session{
+		UserId:    userId,
+		UserAgent: userAgent,
+		Ip:        ip,
+	})
+}
+
+// CheckSession also refuses sessions older than the refresh token TTL, their
+// tokens have expired anyway, and sessions of disabled or deleted users.
+// Tokens issued before sessions existed carry no session and are refused,
+// their users have to log in again.
+func (a *AuthService) CheckSession(ctx context.Context, claims *types.CustomClaims) error {
+	if claims.Sid == 0 {
+		return types.ErrSessionRevoked
+	}
+
+	session, err := a.Store.GetSession(ctx, claims.Sid)
+	if errors.Is(err, types.ErrNotFound) {
+		return types.ErrSessionRevoked
+	}
+	if err != nil {
+		return err
+	}
+	if session.UserId != claims.Uid || session.RevokedAt != nil || time.Since(session.CreatedAt) > a.refreshTokenTTL {
+		return types.ErrSessionRevoked
+	}
+
+	if time.Since(session.LastSeenAt) > sessionTouchInterval {
+		return a.Store.TouchSession(ctx, session.Id, time.Now())
+	}
+	return nil
+}
+
+func (a *AuthService) ListSessions(ctx context.Context, userId int) ([]types.Session, error) {
+	return a.Store.ListSessions(ctx, userId, time.Now().Add(-a.refreshTokenTTL))
+}
+
+func (a *AuthService) RevokeSession(ctx context.Context, userId, sessionId int) error {
+	return a.Store.RevokeSession(ctx, userId, sessionId)
+}
+
+func (a *AuthService) RevokeSessions(ctx context.Context, userId int) error {
+	return a.Store.RevokeSessions(ctx, userId)
+}
@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"context"
 	"crypto/rsa"
+	"errors"
 	"log"
 	"testing"
 	"time"
@@ -12,16 +14,16 @@ import (
 )
 
 func TestSignJwt(t *testing.T) {
+	t.Parallel()
 	pvtKey, pubKey, err := getMockKeys()
 	if err != nil {
 		t.Error("error creating mock keys")
 		return
 	}
-	config.Env.PrivateKey = pvtKey
-	config.Env.PublicKey = pubKey
-
-	type mockAuthStore struct{}
-	mockAuthService := NewAuthService(&mockAuthStore{})
+	mockAuthService := NewAuthService(NewMemoryStore(nil), config.AuthConfig{
+		PrivateKey: pvtKey,
+		PublicKey:  pubKey,
+	})
 
 	token, err := mockAuthService.SignJwt(time.Second*time.Duration(5), types.CustomClaims{
 		Uid: 1,
@@ -36,16 +38,16 @@ func TestSignJwt(t *testing.T) {
 }
 
 func TestVerifyToken(t *testing.T) {
+	t.Parallel()
 	pvtKey, pubKey, err := getMockKeys()
 	if err != nil {
 		t.Error("error creating mock keys")
 		return
 	}
-	config.Env.PrivateKey = pvtKey
-	config.Env.PublicKey = pubKey
-
-	type mockAuthStore struct{}
-	mockAuthService := NewAuthService(&mockAuthStore{})
+	mockAuthService := NewAuthService(NewMemoryStore(nil), config.AuthConfig{
+		PrivateKey: pvtKey,
+		PublicKey:  pubKey,
+	})
 
 	token, err := mockAuthService.SignJwt(time.Second*time.Duration(5), types.CustomClaims{
 		Uid: 1,
@@ -78,6 +80,146 @@ func TestVerifyToken(t *testing.T) {
 	}
 }
 
+func TestInviteTokens(t *testing.T) {
+	t.Parallel()
+	pvtKey, pubKey, err := getMockKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authService := NewAuthService(NewMemoryStore(nil), config.AuthConfig{
+		PrivateKey: pvtKey,
+		PublicKey:  pubKey,
+	})
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	token, err := authService.SignInvite(42, expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, exp, err := authService.VerifyInvite(token)
+	if err != nil || id != 42 || !exp.Equal(expiresAt) {
+		t.Fatalf("expected invite 42 expiring at %v, got %d at %v (%v)", expiresAt, id, exp, err)
+	}
+
+	if _, err := authService.VerifyToken(token); err == nil {
+		t.Error("expected an invite token to be refused as an access token")
+	}
+	accessToken, err := authService.SignJwt(time.Minute, types.CustomClaims{Uid: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := authService.VerifyInvite(accessToken); err == nil {
+		t.Error("expected an access token to be refused as an invite")
+	}
+
+	expired, err := authService.SignInvite(42, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := authService.VerifyInvite(expired); err == nil {
+		t.Error("expected an expired invite to be refused")
+	}
+}
+
+func TestPasswordResetTokens(t *testing.T) {
+	t.Parallel()
+	pvtKey, pubKey, err := getMockKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authService := NewAuthService(NewMemoryStore(nil), config.AuthConfig{
+		PrivateKey: pvtKey,
+		PublicKey:  pubKey,
+	})
+
+	resetAt := time.Now().UTC().Truncate(time.Microsecond)
+	token, err := authService.SignPasswordReset(7, resetAt, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, at, err := authService.VerifyPasswordReset(token)
+	if err != nil || id != 7 || !at.Equal(resetAt) {
+		t.Fatalf("expected user 7 reset at %v, got %d at %v (%v)", resetAt, id, at, err)
+	}
+
+	if _, err := authService.VerifyToken(token); err == nil {
+		t.Error("expected a reset token to be refused as an access token")
+	}
+	if _, _, err := authService.VerifyInvite(token); err == nil {
+		t.Error("expected a reset token to be refused as an invite")
+	}
+	invite, err := authService.SignInvite(7, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := authService.VerifyPasswordReset(invite); err == nil {
+		t.Error("expected an invite token to be refused as a reset token")
+	}
+
+	expired, err := authService.SignPasswordReset(7, resetAt, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := authService.VerifyPasswordReset(expired); err == nil {
+		t.Error("expected an expired reset token to be refused")
+	}
+}
+
+func TestCheckSession(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := NewMemoryStore(nil)
+	authService := NewAuthService(store, config.AuthConfig{RefreshTokenTTL: time.Hour})
+
+	sid, err := authService.StartSession(ctx, 1, "Firefox", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := authService.CheckSession(ctx, &types.CustomClaims{Uid: 1, Sid: sid}); err != nil {
+		t.Fatalf("expected an active session, got %v", err)
+	}
+
+	// last seen is only written once it is out of date
+	stale := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	if err := store.TouchSession(ctx, sid, stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := authService.CheckSession(ctx, &types.CustomClaims{Uid: 1, Sid: sid}); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := store.GetSession(ctx, sid); !s.LastSeenAt.After(stale) {
+		t.Errorf("expected last seen to move on, got %v", s.LastSeenAt)
+	}
+
+	other, err := authService.StartSession(ctx, 1, "Chrome", "10.0.0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := authService.RevokeSession(ctx, 1, other); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := authService.ListSessions(ctx, 1)
+	if err != nil || len(sessions) != 1 || sessions[0].Id != sid {
+		t.Errorf("expected only session %d to be listed, got %+v (%v)", sid, sessions, err)
+	}
+
+	expired := NewAuthService(store, config.AuthConfig{RefreshTokenTTL: time.Nanosecond})
+	refused := map[string]*types.CustomClaims{
+		"no session":      {Uid: 1},
+		"unknown session": {Uid: 1, Sid: 12345},
+		"another user":    {Uid: 2, Sid: sid},
+		"revoked session": {Uid: 1, Sid: other},
+	}
+	for name, claims := range refused {
+		if err := authService.CheckSession(ctx, claims); !errors.Is(err, types.ErrSessionRevoked) {
+			t.Errorf("%s: expected ErrSessionRevoked, got %v", name, err)
+		}
+	}
+	if err := expired.CheckSession(ctx, &types.CustomClaims{Uid: 1, Sid: sid}); !errors.Is(err, types.ErrSessionRevoked) {
+		t.Errorf("expected a session older than the refresh token TTL to be refused, got %v", err)
+	}
+}
+
 func getMockKeys() (*rsa.PrivateKey, *rsa.PublicKey, error) {
 	publicPem := `-----BEGIN PUBLIC KEY-----
 MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAu1SU1LfVLPHCozMxH2Mo
@@ -1,13 +1,114 @@
 package auth
 
-import "github.com/jackc/pgx/v5/pgxpool"
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/db"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/jackc/pgx/v5"
+)
+
+const sessionColumns = "id, userId, userAgent, ip, createdAt, lastSeenAt, revokedAt"
 
 type AuthStore struct {
-	db *pgxpool.Pool
+	db db.Querier
 }
 
-func NewAuthStore(db *pgxpool.Pool) *AuthStore {
+func NewAuthStore(db db.Querier) *AuthStore {
 	return &AuthStore{
 		db: db,
 	}
 }
+
+func (s *AuthStore) CreateSession(ctx context.Context, session types.Session) (int, error) {
+	var id int
+	err := s.db.QueryRow(ctx,
+		"insert into sessions (userId, userAgent, ip) values ($1, $2, $3) returning id",
+		session.UserId, session.UserAgent, session.Ip,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *AuthStore) GetSession(ctx context.Context, id int) (*types.Session, error) {
+	session, err := scanSession(s.db.QueryRow(ctx,
+		"select "+sessionColumns+" from sessions where id = $1 and userId in (select id from users where disabledAt is null and deletedAt is null)",
+		id,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *AuthStore) ListSessions(ctx context.Context, userId int, since time.Time) ([]types.Session, error) {
+	rows, err := s.db.Query(ctx,
+		"select "+sessionColumns+" from sessions where userId = $1 and revokedAt is null and createdAt > $2 order by id desc",
+		userId, since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []types.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+func scanSession(row pgx.Row) (*types.Session, error) {
+	session := new(types.Session)
+	err := row.Scan(
+		&session.Id,
+		&session.UserId,
+		&session.UserAgent,
+		&session.Ip,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *AuthStore) TouchSession(ctx context.Context, id int, at time.Time) error {
+	tag, err := s.db.Exec(ctx, "update sessions set lastSeenAt = $2 where id = $1", id, at)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrNotFound
+	}
+	return nil
+}
+
+func (s *AuthStore) RevokeSession(ctx context.Context, userId, id int) error {
+	tag, err := s.db.Exec(ctx, "update sessions set revokedAt = now() where id = $1 and userId = $2 and revokedAt is null", id, userId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrNotFound
+	}
+	return nil
+}
+
+func (s *AuthStore) RevokeSessions(ctx context.Context, userId int) error {
+	_, err := s.db.Exec(ctx, "update sessions set revokedAt = now() where userId = $1 and revokedAt is null", userId)
+	return err
+}
@@ -0,0 +1,29 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/auth"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/user"
+	"github.com/SufyaanKhateeb/college-placement-app-api/storetest"
+	"github.com/SufyaanKhateeb/college-placement-app-api/testdb"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+func TestMain(m *testing.M) {
+	testdb.Main(m)
+}
+
+func TestStore(t *testing.T) {
+	storetest.AuthStore(t, func(t *testing.T) (types.AuthStore, types.UserStore) {
+		pool := testdb.New(t)
+		return auth.NewAuthStore(pool), user.NewStore(pool)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	storetest.AuthStore(t, func(t *testing.T) (types.AuthStore, types.UserStore) {
+		users := user.NewMemoryStore()
+		return auth.NewMemoryStore(users), users
+	})
+}
@@ -0,0 +1,98 @@
+package calendar
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+const prodId = "-//placement-app//calendar//EN"
+
+const icsTimeFormat = "20060102T150405Z"
+
+// WriteICS writes events as an RFC 5545 VCALENDAR.
+func WriteICS(w io.Writer, name string, events []types.CalendarEvent) error {
+	var b strings.Builder
+	line := func(l string) {
+		b.WriteString(fold(l))
+		b.WriteString("\r\n")
+	}
+
+	now := time.Now().UTC().Format(icsTimeFormat)
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:" + prodId)
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escape(name))
+	for _, e := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + escape(e.Uid))
+		line("DTSTAMP:" + now)
+		line("DTSTART:" + e.Start.UTC().Format(icsTimeFormat))
+		if !e.End.IsZero() {
+			line("DTEND:" + e.End.UTC().Format(icsTimeFormat))
+		}
+		line("SUMMARY:" + escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:" + escape(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION:" + escape(e.Location))
+		}
+		if e.Url != "" {
+			line("URL:" + e.Url)
+		}
+		if e.Category != "" {
+			line("CATEGORIES:" + escape(e.Category))
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escape(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// fold splits content lines longer than 75 octets, continuing them on lines
+// that start with a space. It never splits a multi-byte UTF-8 sequence.
+func fold(l string) string {
+	if len(l) <= 75 {
+		return l
+	}
+
+	var b strings.Builder
+	limit := 75
+	for len(l) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(l[cut]) {
+			cut--
+		}
+		b.WriteString(l[:cut])
+		b.WriteString("\r\n ")
+		l = l[cut:]
+		// continuation lines lose one octet to the leading space
+		limit = 74
+	}
+	b.WriteString(l)
+	return b.String()
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
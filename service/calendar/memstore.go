@@ -0,0 +1,99 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+type memoryFeed struct {
+	userId  int
+	revoked bool
+}
+
+// MemoryStore is an in-memory types.CalendarStore for tests and demo mode.
+type MemoryStore struct {
+	mu    sync.Mutex
+	feeds map[string]*memoryFeed
+	// users stands in for the join on users in Store.GetUserIdByFeedToken,
+	// nil treats every user as active for tests that have no user store
+	users types.UserStore
+}
+
+func NewMemoryStore(users types.UserStore) *MemoryStore {
+	return &MemoryStore{
+		feeds: map[string]*memoryFeed{},
+		users: users,
+	}
+}
+
+func (s *MemoryStore) CreateFeedToken(ctx context.Context, userId int, tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.feeds[tokenHash]; ok {
+		return fmt.Errorf("calendar feed token already exists")
+	}
+	s.revoke(userId)
+	s.feeds[tokenHash] = &memoryFeed{userId: userId}
+	return nil
+}
+
+func (s *MemoryStore) GetUserIdByFeedToken(ctx context.Context, tokenHash string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feed, ok := s.feeds[tokenHash]
+	if !ok || feed.revoked {
+		return 0, types.ErrNotFound
+	}
+	if s.users != nil {
+		// deleted users are not found either
+		u, err := s.users.GetUserById(ctx, feed.userId)
+		if err != nil {
+			return 0, err
+		}
+		if u.DisabledAt != nil {
+			return 0, types.ErrNotFound
+		}
+	}
+	return feed.userId, nil
+}
+
+func (s *MemoryStore) RevokeFeedTokens(ctx context.Context, userId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revoke(userId)
+	return nil
+}
+
+func (s *MemoryStore) revoke(userId int) {
+	for _, feed := range s.feeds {
+		if feed.userId == userId {
+			feed.revoked = true
+		}
+	}
+}
+
+// Snapshot returns a function that puts the store back as it is now, memtx
+// rolls back failed units of work with it.
+func (s *MemoryStore) Snapshot() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feeds := make(map[string]memoryFeed, len(s.feeds))
+	for hash, feed := range s.feeds {
+		feeds[hash] = *feed
+	}
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.feeds = make(map[string]*memoryFeed, len(feeds))
+		for hash, feed := range feeds {
+			s.feeds[hash] = &feed
+		}
+	}
+}
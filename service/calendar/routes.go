@@ -0,0 +1,160 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/middlewares"
+	"github.com/SufyaanKhateeb/college-placement-app-api/reqctx"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	Store       types.CalendarStore
+	AuthService types.AuthService
+	Cookie      config.CookieConfig
+	PublicUrl   string
+	Sources     []types.CalendarSource
+}
+
+func NewHandler(s types.CalendarStore, authService types.AuthService, cookie config.CookieConfig, publicUrl string, sources ...types.CalendarSource) *Handler {
+	return &Handler{
+		Store:       s,
+		AuthService: authService,
+		Cookie:      cookie,
+		PublicUrl:   publicUrl,
+		Sources:     sources,
+	}
+}
+
+func (h *Handler) RegisterRoutes(r *chi.Mux) {
+	// Public Routes
+	// Calendar clients can't send cookies, the secret token in the URL
+	// identifies the user
+	r.Group(func(r chi.Router) {
+		r.Get("/calendar/feed/{token}.ics", h.handleFeed)
+	})
+
+	// Private Routes
+	// Require Authentication
+	r.Group(func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware(h.AuthService, h.Cookie), middlewares.RequireUser)
+		r.Post("/calendar/feed", h.handleCreateFeed)
+		r.Delete("/calendar/feed", h.handleRevokeFeed)
+		r.Get("/calendar/events/{uid}.ics", h.handleEvent)
+	})
+}
+
+func (h *Handler) handleFeed(w http.ResponseWriter, r *http.Request) {
+	userId, err := h.Store.GetUserIdByFeedToken(r.Context(), hashToken(chi.URLParam(r, "token")))
+	if errors.Is(err, types.ErrNotFound) {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusNotFound, utils.CodeFeedNotFound, "calendar feed not found"))
+		return
+	}
+	if err != nil {
+		utils.WriteInternalError(w, r, "looking up calendar feed failed", err)
+		return
+	}
+
+	events, err := h.userEvents(r, userId)
+	if err != nil {
+		utils.WriteInternalError(w, r, "loading calendar events failed", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=900")
+	WriteICS(w, "Placements", events)
+}
+
+func (h *Handler) handleCreateFeed(w http.ResponseWriter, r *http.Request) {
+	ctxUser := reqctx.MustUser(r.Context())
+
+	token, err := newFeedToken()
+	if err != nil {
+		utils.WriteInternalError(w, r, "generating feed token failed", err)
+		return
+	}
+
+	if err := h.Store.CreateFeedToken(r.Context(), ctxUser.Id, hashToken(token)); err != nil {
+		utils.WriteInternalError(w, r, "creating feed token failed", err)
+		return
+	}
+
+	utils.WriteJson(w, http.StatusCreated, types.CalendarFeedDto{
+		Url: h.PublicUrl + "/api/v1/calendar/feed/" + token + ".ics",
+	})
+}
+
+func (h *Handler) handleRevokeFeed(w http.ResponseWriter, r *http.Request) {
+	ctxUser := reqctx.MustUser(r.Context())
+
+	if err := h.Store.RevokeFeedTokens(r.Context(), ctxUser.Id); err != nil {
+		utils.WriteInternalError(w, r, "revoking feed tokens failed", err)
+		return
+	}
+
+	utils.WriteJson(w, http.StatusAccepted, nil)
+}
+
+func (h *Handler) handleEvent(w http.ResponseWriter, r *http.Request) {
+	ctxUser := reqctx.MustUser(r.Context())
+
+	uid := chi.URLParam(r, "uid")
+
+	events, err := h.userEvents(r, ctxUser.Id)
+	if err != nil {
+		utils.WriteInternalError(w, r, "loading calendar events failed", err)
+		return
+	}
+
+	for _, e := range events {
+		if e.Uid == uid {
+			w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", uid+".ics"))
+			WriteICS(w, e.Summary, []types.CalendarEvent{e})
+			return
+		}
+	}
+
+	utils.WriteProblem(w, utils.NewProblem(http.StatusNotFound, utils.CodeEventNotFound, "event not found"))
+}
+
+func (h *Handler) userEvents(r *http.Request, userId int) ([]types.CalendarEvent, error) {
+	events := []types.CalendarEvent{}
+	for _, source := range h.Sources {
+		e, err := source.UserEvents(r.Context(), userId)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e...)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+	return events, nil
+}
+
+func newFeedToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// only the hash is stored, a leaked database doesn't leak working feed URLs
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,214 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/auth"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/placement"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/user"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestCalendarHandlers(t *testing.T) {
+	t.Parallel()
+	store := NewMemoryStore(nil)
+	source := &mockCalendarSource{events: []types.CalendarEvent{
+		{
+			Uid:     "deadline-2@placement-app",
+			Summary: "Application deadline: Acme, Inc.",
+			Start:   time.Date(2024, 10, 2, 18, 30, 0, 0, time.UTC),
+		},
+		{
+			Uid:     "interview-7@placement-app",
+			Summary: "Interview",
+			Start:   time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC),
+			End:     time.Date(2024, 10, 1, 9, 30, 0, 0, time.UTC),
+		},
+	}}
+	handler := NewHandler(store, newAuthService(t), config.Default().Cookie, "http://localhost:8090", source)
+
+	router := chi.NewRouter()
+	router.Get("/calendar/feed/{token}.ics", handler.handleFeed)
+
+	t.Run("should serve feed for a valid token without cookies", func(t *testing.T) {
+		if err := store.CreateFeedToken(context.Background(), 1, hashToken("secret")); err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest(http.MethodGet, "/calendar/feed/secret.ics", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got %d", http.StatusOK, rr.Code)
+		}
+		if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+			t.Errorf("expected text/calendar content type, got %s", ct)
+		}
+
+		body := rr.Body.String()
+		if strings.Count(body, "BEGIN:VEVENT") != 2 {
+			t.Errorf("expected 2 events in feed, got:\n%s", body)
+		}
+		if strings.Index(body, "interview-7") > strings.Index(body, "deadline-2") {
+			t.Error("expected events to be sorted by start time")
+		}
+		if !strings.Contains(body, `SUMMARY:Application deadline: Acme\, Inc.`) {
+			t.Error("expected summary text to be escaped")
+		}
+	})
+
+	t.Run("should not serve feed for a revoked token", func(t *testing.T) {
+		store.RevokeFeedTokens(context.Background(), 1)
+
+		req, err := http.NewRequest(http.MethodGet, "/calendar/feed/secret.ics", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		expectProblem(t, rr, http.StatusNotFound, utils.CodeFeedNotFound)
+	})
+}
+
+func TestPlacementFeed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	users := user.NewMemoryStore()
+	store := NewMemoryStore(users)
+	placements := placement.NewMemoryStore()
+	handler := NewHandler(store, newAuthService(t), config.Default().Cookie, "http://localhost:8090", placement.NewCalendarSource(placements, users))
+
+	router := chi.NewRouter()
+	router.Get("/calendar/feed/{token}.ics", handler.handleFeed)
+
+	studentId, err := users.CreateUser(ctx, types.User{Email: "student@example.com", UType: types.UTypeStudent, Branch: "CSE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	companyId, err := placements.CreateCompany(ctx, types.Company{Name: "Acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	talkAt := time.Date(2024, 9, 20, 10, 0, 0, 0, time.UTC)
+	driveId, err := placements.CreateDrive(ctx, types.Drive{
+		CompanyId:           companyId,
+		Title:               "SDE",
+		EligibleBranches:    []string{"CSE"},
+		ApplicationDeadline: time.Date(2024, 9, 25, 18, 30, 0, 0, time.UTC),
+		TalkAt:              &talkAt,
+		TalkLocation:        "Seminar hall",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	appId, err := placements.CreateApplication(ctx, types.Application{DriveId: driveId, StudentId: studentId, Status: types.ApplicationStatusInterview})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slotStart := time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC)
+	if _, err := placements.CreateInterviewSlot(ctx, types.InterviewSlot{ApplicationId: appId, StartsAt: slotStart, EndsAt: slotStart.Add(30 * time.Minute), Location: "Room 4"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateFeedToken(ctx, studentId, hashToken("secret")); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/calendar/feed/secret.ics", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+	body := rr.Body.String()
+	for _, want := range []string{
+		"UID:talk-1@placement-app\r\nDTSTAMP:",
+		"SUMMARY:Pre-placement talk: Acme\\, SDE",
+		"LOCATION:Seminar hall",
+		"UID:deadline-1@placement-app",
+		"DTSTART:20240925T183000Z",
+		"UID:interview-1@placement-app",
+		"DTEND:20241001T093000Z",
+		"LOCATION:Room 4",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected feed to contain %q, got:\n%s", want, body)
+		}
+	}
+	if strings.Count(body, "BEGIN:VEVENT") != 3 {
+		t.Errorf("expected 3 events in feed, got:\n%s", body)
+	}
+
+	// disabling the student stops the feed at once
+	if err := users.SetUserDisabled(ctx, studentId, true); err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	expectProblem(t, rr, http.StatusNotFound, utils.CodeFeedNotFound)
+}
+
+func TestFold(t *testing.T) {
+	t.Parallel()
+	line := "DESCRIPTION:" + strings.Repeat("é", 80)
+	for _, l := range strings.Split(fold(line), "\r\n") {
+		if len(l) > 75 {
+			t.Errorf("expected folded line to be at most 75 octets, got %d", len(l))
+		}
+		if !strings.HasPrefix(l, "DESCRIPTION") && !strings.HasPrefix(l, " ") {
+			t.Errorf("expected continuation line to start with a space, got %q", l)
+		}
+	}
+	if strings.ReplaceAll(fold(line), "\r\n ", "") != line {
+		t.Error("expected unfolding to restore the original line")
+	}
+}
+
+func expectProblem(t *testing.T, rr *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	if rr.Code != status {
+		t.Fatalf("expected status code %d, got %d: %s", status, rr.Code, rr.Body)
+	}
+	var problem utils.Problem
+	if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Code != code {
+		t.Errorf("expected code %s, got %s", code, problem.Code)
+	}
+}
+
+type mockCalendarSource struct {
+	events []types.CalendarEvent
+}
+
+func (s *mockCalendarSource) UserEvents(ctx context.Context, userId int) ([]types.CalendarEvent, error) {
+	return s.events, nil
+}
+
+// newAuthService returns a real auth service on memory stores with fresh keys.
+func newAuthService(t *testing.T) *auth.AuthService {
+	t.Helper()
+	cfg := config.Default()
+	if err := cfg.Auth.GenerateKeys(); err != nil {
+		t.Fatal(err)
+	}
+	return auth.NewAuthService(auth.NewMemoryStore(nil), cfg.Auth)
+}
@@ -0,0 +1,63 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/db"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/jackc/pgx/v5"
+)
+
+type Store struct {
+	db db.Querier
+}
+
+func NewStore(db db.Querier) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// CreateFeedToken stores a new feed token for the user, revoking any
+// previous one so that only the latest feed URL keeps working.
+func (s *Store) CreateFeedToken(ctx context.Context, userId int, tokenHash string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, "update calendar_feeds set revokedAt = now() where userId = $1 and revokedAt is null", userId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "insert into calendar_feeds (userId, tokenHash) values ($1, $2)", userId, tokenHash)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *Store) GetUserIdByFeedToken(ctx context.Context, tokenHash string) (int, error) {
+	var userId int
+	err := s.db.QueryRow(ctx,
+		"select userId from calendar_feeds where tokenHash = $1 and revokedAt is null and userId in (select id from users where disabledAt is null and deletedAt is null)",
+		tokenHash,
+	).Scan(&userId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, types.ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return userId, nil
+}
+
+func (s *Store) RevokeFeedTokens(ctx context.Context, userId int) error {
+	_, err := s.db.Exec(ctx, "update calendar_feeds set revokedAt = now() where userId = $1 and revokedAt is null", userId)
+	return err
+}
@@ -0,0 +1,28 @@
+package calendar
+
+import (
+	"testing"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/user"
+	"github.com/SufyaanKhateeb/college-placement-app-api/storetest"
+	"github.com/SufyaanKhateeb/college-placement-app-api/testdb"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+func TestMain(m *testing.M) {
+	testdb.Main(m)
+}
+
+func TestStore(t *testing.T) {
+	storetest.CalendarStore(t, func(t *testing.T) (types.CalendarStore, types.UserStore) {
+		pool := testdb.New(t)
+		return NewStore(pool), user.NewStore(pool)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	storetest.CalendarStore(t, func(t *testing.T) (types.CalendarStore, types.UserStore) {
+		users := user.NewMemoryStore()
+		return NewMemoryStore(users), users
+	})
+}
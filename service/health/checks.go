@@ -0,0 +1,35 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func DbCheck(db *pgxpool.Pool) CheckFunc {
+	return func(ctx context.Context) error {
+		return db.Ping(ctx)
+	}
+}
+
+// MigrationCheck fails when the database schema is not at the version this
+// binary was built with, or a migration was left dirty.
+func MigrationCheck(db *pgxpool.Pool, expected uint) CheckFunc {
+	return func(ctx context.Context) error {
+		var version uint
+		var dirty bool
+		err := db.QueryRow(ctx, "select version, dirty from schema_migrations limit 1").Scan(&version, &dirty)
+		if err != nil {
+			return err
+		}
+
+		if dirty {
+			return fmt.Errorf("migration %d is dirty", version)
+		}
+		if version != expected {
+			return fmt.Errorf("schema version %d, expected %d", version, expected)
+		}
+		return nil
+	}
+}
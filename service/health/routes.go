@@ -0,0 +1,109 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/reqctx"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+}
+
+type ReadinessDto struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+type Handler struct {
+	checks       []check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewHandler(timeout time.Duration) *Handler {
+	return &Handler{
+		timeout: timeout,
+	}
+}
+
+// AddCheck registers a dependency that must be healthy for the server to
+// receive traffic. It must be called before the routes are served.
+func (h *Handler) AddCheck(name string, fn CheckFunc) {
+	h.checks = append(h.checks, check{name: name, fn: fn})
+}
+
+// SetShuttingDown makes readiness fail so load balancers stop routing new
+// requests while in-flight ones drain.
+func (h *Handler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+func (h *Handler) RegisterRoutes(r *chi.Mux) {
+	r.Get("/healthz", h.handleLive)
+	r.Get("/readyz", h.handleReady)
+}
+
+func (h *Handler) handleLive(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJson(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handler) handleReady(w http.ResponseWriter, r *http.Request) {
+	if h.shuttingDown.Load() {
+		utils.WriteJson(w, http.StatusServiceUnavailable, ReadinessDto{Status: "shutting down", Checks: []CheckResult{}})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	// run checks concurrently so one slow dependency doesn't add up with the rest
+	results := make([]CheckResult, len(h.checks))
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := c.fn(ctx)
+			results[i] = CheckResult{
+				Name:      c.name,
+				Status:    "ok",
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			// readiness is public, errors can name hosts and users so they
+			// are only logged
+			if err != nil {
+				results[i].Status = "failing"
+				reqctx.Logger(r.Context()).Warn("readiness check failing", "check", c.name, "err", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	dto := ReadinessDto{Status: "ok", Checks: results}
+	for _, res := range results {
+		if res.Status != "ok" {
+			status = http.StatusServiceUnavailable
+			dto.Status = "failing"
+		}
+	}
+
+	utils.WriteJson(w, status, dto)
+}
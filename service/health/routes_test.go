@@ -0,0 +1,84 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestHealthHandlers(t *testing.T) {
+	failing := false
+	handler := NewHandler(time.Second)
+	handler.AddCheck("database", func(ctx context.Context) error {
+		return nil
+	})
+	handler.AddCheck("mailer", func(ctx context.Context) error {
+		if failing {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	})
+
+	router := chi.NewRouter()
+	handler.RegisterRoutes(router)
+
+	get := func(path string) (*httptest.ResponseRecorder, ReadinessDto) {
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		var dto ReadinessDto
+		json.Unmarshal(rr.Body.Bytes(), &dto)
+		return rr, dto
+	}
+
+	t.Run("should be ready when all checks pass", func(t *testing.T) {
+		rr, dto := get("/readyz")
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected status code %d, got %d", http.StatusOK, rr.Code)
+		}
+		if len(dto.Checks) != 2 || dto.Checks[0].Name != "database" {
+			t.Errorf("expected per-check results in registration order, got %+v", dto.Checks)
+		}
+	})
+
+	t.Run("should not be ready when a check fails", func(t *testing.T) {
+		failing = true
+		defer func() { failing = false }()
+
+		rr, dto := get("/readyz")
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status code %d, got %d", http.StatusServiceUnavailable, rr.Code)
+		}
+		if dto.Checks[1].Status != "failing" {
+			t.Errorf("expected mailer check to be failing, got %+v", dto.Checks[1])
+		}
+		if strings.Contains(rr.Body.String(), "connection refused") {
+			t.Errorf("expected the check error to stay private, got %s", rr.Body)
+		}
+	})
+
+	t.Run("should not be ready while shutting down but stay live", func(t *testing.T) {
+		handler.SetShuttingDown()
+
+		rr, _ := get("/readyz")
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status code %d, got %d", http.StatusServiceUnavailable, rr.Code)
+		}
+
+		rr, _ = get("/healthz")
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected status code %d, got %d", http.StatusOK, rr.Code)
+		}
+	})
+}
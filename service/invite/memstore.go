@@ -0,0 +1,159 @@
+package invite
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// MemoryStore is an in-memory types.InviteStore for tests and demo mode.
+type MemoryStore struct {
+	mu      sync.Mutex
+	invites []types.Invite
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func (s *MemoryStore) CreateInvite(ctx context.Context, inv types.Invite) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv.Id = len(s.invites) + 1
+	inv.SendCount = 1
+	inv.CreatedAt = now()
+	inv.LastSentAt = inv.CreatedAt
+	inv.AcceptedAt = nil
+	inv.AcceptedUserId = 0
+	inv.RevokedAt = nil
+	s.invites = append(s.invites, inv)
+	return inv.Id, nil
+}
+
+func (s *MemoryStore) GetInvite(ctx context.Context, id int) (*types.Invite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	copied := *inv
+	return &copied, nil
+}
+
+func (s *MemoryStore) get(id int) (*types.Invite, error) {
+	if id < 1 || id > len(s.invites) {
+		return nil, types.ErrNotFound
+	}
+	return &s.invites[id-1], nil
+}
+
+func (s *MemoryStore) ListInvites(ctx context.Context, filter types.InviteFilter) ([]types.Invite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := statusConds[filter.Status]; filter.Status != "" && !ok {
+		return nil, fmt.Errorf("unknown invite status %s", filter.Status)
+	}
+
+	t := time.Now()
+	invites := []types.Invite{}
+	skipped := 0
+	for i := len(s.invites) - 1; i >= 0; i-- {
+		inv := s.invites[i]
+		if filter.Status != "" && inv.Status(t) != filter.Status ||
+			filter.Email != "" && !strings.EqualFold(inv.Email, filter.Email) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		if filter.Limit > 0 && len(invites) == filter.Limit {
+			break
+		}
+		invites = append(invites, inv)
+	}
+	return invites, nil
+}
+
+// pending returns the invite if it was neither accepted nor revoked.
+func (s *MemoryStore) pending(id int) (*types.Invite, error) {
+	inv, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if inv.AcceptedAt != nil || inv.RevokedAt != nil {
+		return nil, types.ErrInviteClosed
+	}
+	return inv, nil
+}
+
+func (s *MemoryStore) RenewInvite(ctx context.Context, id int, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, err := s.pending(id)
+	if err != nil {
+		return err
+	}
+	inv.ExpiresAt = expiresAt
+	inv.SendCount++
+	inv.LastSentAt = now()
+	return nil
+}
+
+func (s *MemoryStore) RevokeInvite(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, err := s.pending(id)
+	if err != nil {
+		return err
+	}
+	t := now()
+	inv.RevokedAt = &t
+	return nil
+}
+
+func (s *MemoryStore) AcceptInvite(ctx context.Context, id int, userId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, err := s.pending(id)
+	if err != nil {
+		return err
+	}
+	t := now()
+	if !t.Before(inv.ExpiresAt) {
+		return types.ErrInviteClosed
+	}
+	inv.AcceptedAt = &t
+	inv.AcceptedUserId = userId
+	return nil
+}
+
+// Snapshot returns a function that puts the store back as it is now, memtx
+// rolls back failed units of work with it.
+func (s *MemoryStore) Snapshot() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invites := slices.Clone(s.invites)
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.invites = invites
+	}
+}
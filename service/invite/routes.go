@@ -0,0 +1,323 @@
+package invite
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/middlewares"
+	"github.com/SufyaanKhateeb/college-placement-app-api/reqctx"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/auth"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+type Handler struct {
+	Store        types.InviteStore
+	Users        types.UserStore
+	Tx           types.Transactor
+	AuthService  types.AuthService
+	Signer       types.InviteSigner
+	Cookie       config.CookieConfig
+	Registration config.RegistrationConfig
+}
+
+func NewHandler(s types.InviteStore, users types.UserStore, tx types.Transactor, authService types.AuthService, signer types.InviteSigner, cookie config.CookieConfig, registration config.RegistrationConfig) *Handler {
+	return &Handler{
+		Store:        s,
+		Users:        users,
+		Tx:           tx,
+		AuthService:  authService,
+		Signer:       signer,
+		Cookie:       cookie,
+		Registration: registration,
+	}
+}
+
+func (h *Handler) RegisterRoutes(r *chi.Mux) {
+	// Public Routes
+	r.Group(func(r chi.Router) {
+		r.Post("/invites/accept", h.handleAccept)
+	})
+
+	// Admin Routes
+	r.Group(func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware(h.AuthService, h.Cookie), middlewares.RequireUser, middlewares.RequireRole(types.UTypeAdmin))
+		r.Post("/admin/invites", h.handleCreate)
+		r.Get("/admin/invites", h.handleList)
+		r.Post("/admin/invites/{id}/resend", h.handleResend)
+		r.Delete("/admin/invites/{id}", h.handleRevoke)
+	})
+}
+
+func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	ctxUser := reqctx.MustUser(r.Context())
+
+	var payload types.CreateInvitePayload
+	if err := utils.ParseJson(r, &payload); err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err)
+		return
+	}
+	payload.Email = utils.NormalizeEmail(payload.Email)
+
+	if err := utils.GetValidator().Struct(payload); err != nil {
+		utils.WriteProblem(w, utils.ValidationProblem(err))
+		return
+	}
+
+	exists, err := h.Users.CheckUserWithEmailExits(r.Context(), payload.Email)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	if exists {
+		writeStoreError(w, r, types.ErrDuplicateEmail)
+		return
+	}
+
+	id, err := h.Store.CreateInvite(r.Context(), types.Invite{
+		Email:     payload.Email,
+		UType:     payload.UType,
+		Company:   payload.Company,
+		CreatedBy: ctxUser.Id,
+		ExpiresAt: h.expiry(),
+	})
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	inv, err := h.Store.GetInvite(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	middlewares.SetAuditTarget(r, "invite", strconv.Itoa(id), nil, inv)
+
+	h.writeInvite(w, r, http.StatusCreated, inv)
+}
+
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r.URL.Query())
+	if err != nil {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInvalidQuery, err.Error()))
+		return
+	}
+
+	invites, err := h.Store.ListInvites(r.Context(), filter)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	dtos := make([]types.InviteDto, 0, len(invites))
+	for _, inv := range invites {
+		dtos = append(dtos, types.InviteDto{Invite: inv, Status: inv.Status(now)})
+	}
+	utils.WriteJson(w, http.StatusOK, dtos)
+}
+
+func (h *Handler) handleResend(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInvalidId, "invalid invite id"))
+		return
+	}
+
+	before, err := h.Store.GetInvite(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	if err := h.Store.RenewInvite(r.Context(), id, h.expiry()); err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	after, err := h.Store.GetInvite(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	middlewares.SetAuditTarget(r, "invite", strconv.Itoa(id), before, after)
+
+	h.writeInvite(w, r, http.StatusOK, after)
+}
+
+func (h *Handler) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInvalidId, "invalid invite id"))
+		return
+	}
+
+	before, err := h.Store.GetInvite(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	if err := h.Store.RevokeInvite(r.Context(), id); err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	after, err := h.Store.GetInvite(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	middlewares.SetAuditTarget(r, "invite", strconv.Itoa(id), before, after)
+
+	utils.WriteJson(w, http.StatusAccepted, nil)
+}
+
+func (h *Handler) handleAccept(w http.ResponseWriter, r *http.Request) {
+	var payload types.AcceptInvitePayload
+	if err := utils.ParseJson(r, &payload); err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.GetValidator().Struct(payload); err != nil {
+		utils.WriteProblem(w, utils.ValidationProblem(err))
+		return
+	}
+
+	id, expiresAt, err := h.Signer.VerifyInvite(payload.Token)
+	if err != nil {
+		reqctx.Logger(r.Context()).Info("invite token rejected", "err", err)
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInviteInvalid, "the invite link is invalid or has expired"))
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(r.Context(), payload.Password)
+	if err != nil {
+		utils.WriteInternalError(w, r, "hashing password failed", err)
+		return
+	}
+
+	var user types.User
+	err = h.Tx.InTx(r.Context(), func(s types.Stores) error {
+		inv, err := s.Invite.GetInvite(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		// a resent invite has a new expiry, links issued before no longer match
+		if inv.Status(time.Now()) != types.InviteStatusPending || !inv.ExpiresAt.Equal(expiresAt) {
+			return types.ErrInviteClosed
+		}
+
+		user = types.User{
+			FirstName: payload.FirstName,
+			LastName:  payload.LastName,
+			Email:     inv.Email,
+			Password:  hashedPassword,
+			UType:     inv.UType,
+			Company:   inv.Company,
+		}
+		if user.Id, err = s.User.CreateUser(r.Context(), user); err != nil {
+			return err
+		}
+		return s.Invite.AcceptInvite(r.Context(), id, user.Id)
+	})
+	if errors.Is(err, types.ErrNotFound) {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInviteInvalid, "the invite link is invalid or has expired"))
+		return
+	}
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	middlewares.SetAuditTarget(r, "invite", strconv.Itoa(id), nil, map[string]any{"acceptedUserId": user.Id})
+
+	reqctx.Logger(r.Context()).Info("invite accepted", "invite_id", id, "user_id", user.Id)
+	utils.WriteJson(w, http.StatusCreated, user)
+}
+
+// expiry is truncated to seconds, the precision of the expiry in the token,
+// so the two can be compared exactly.
+func (h *Handler) expiry() time.Time {
+	return time.Now().Add(h.Registration.InviteTTL).Truncate(time.Second)
+}
+
+// writeInvite responds with the invite and a freshly signed link for it.
+// Links are not stored, an admin who lost one resends the invite.
+func (h *Handler) writeInvite(w http.ResponseWriter, r *http.Request, status int, inv *types.Invite) {
+	token, err := h.Signer.SignInvite(inv.Id, inv.ExpiresAt)
+	if err != nil {
+		utils.WriteInternalError(w, r, "signing invite failed", err)
+		return
+	}
+
+	// config validation has checked InviteUrl
+	link, err := url.Parse(h.Registration.InviteUrl)
+	if err != nil {
+		utils.WriteInternalError(w, r, "building invite link failed", err)
+		return
+	}
+	q := link.Query()
+	q.Set("token", token)
+	link.RawQuery = q.Encode()
+
+	utils.WriteJson(w, status, types.InviteDto{
+		Invite: *inv,
+		Status: inv.Status(time.Now()),
+		Link:   link.String(),
+	})
+}
+
+func writeStoreError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, types.ErrNotFound):
+		utils.WriteProblem(w, utils.NewProblem(http.StatusNotFound, utils.CodeInviteNotFound, "invite not found"))
+	case errors.Is(err, types.ErrInviteClosed):
+		utils.WriteProblem(w, utils.NewProblem(http.StatusGone, utils.CodeInviteClosed, "the invite was already accepted, revoked or replaced by a newer link"))
+	case errors.Is(err, types.ErrDuplicateEmail):
+		utils.WriteProblem(w, utils.NewProblem(http.StatusConflict, utils.CodeEmailTaken, "a user with this email already exists"))
+	default:
+		utils.WriteInternalError(w, r, "invite request failed", err)
+	}
+}
+
+func parseFilter(q url.Values) (types.InviteFilter, error) {
+	filter := types.InviteFilter{
+		Status: q.Get("status"),
+		Email:  utils.NormalizeEmail(q.Get("email")),
+		Limit:  defaultPageSize,
+	}
+	var err error
+
+	statuses := []string{types.InviteStatusPending, types.InviteStatusAccepted, types.InviteStatusRevoked, types.InviteStatusExpired}
+	if filter.Status != "" && !slices.Contains(statuses, filter.Status) {
+		return filter, fmt.Errorf("invalid status %s", filter.Status)
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit <= 0 {
+			return filter, fmt.Errorf("invalid limit %s", v)
+		}
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
+	}
+	if v := q.Get("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil || filter.Offset < 0 {
+			return filter, fmt.Errorf("invalid offset %s", v)
+		}
+	}
+
+	return filter, nil
+}
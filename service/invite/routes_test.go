@@ -0,0 +1,242 @@
+package invite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/auth"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/user"
+	"github.com/SufyaanKhateeb/college-placement-app-api/stores/memtx"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type testServer struct {
+	t           *testing.T
+	router      *chi.Mux
+	users       *user.MemoryStore
+	authService *auth.AuthService
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	cfg := config.Default()
+	if err := cfg.Auth.GenerateKeys(); err != nil {
+		t.Fatal(err)
+	}
+	authService := auth.NewAuthService(auth.NewMemoryStore(nil), cfg.Auth)
+
+	users := user.NewMemoryStore()
+	invites := NewMemoryStore()
+	tx := memtx.New(types.Stores{User: users, Invite: invites})
+	handler := NewHandler(invites, users, tx, authService, authService, cfg.Cookie, cfg.Registration)
+
+	router := chi.NewRouter()
+	handler.RegisterRoutes(router)
+	return &testServer{t: t, router: router, users: users, authService: authService}
+}
+
+// do sends the request signed in with the given role, or anonymously when
+// role is empty.
+func (s *testServer) do(method string, path string, role string, body string) *httptest.ResponseRecorder {
+	s.t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if role != "" {
+		sid, err := s.authService.StartSession(context.Background(), 1, "test", "")
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		token, err := s.authService.SignJwt(s.authService.AccessTokenTTL(), types.CustomClaims{Uid: 1, UType: role, Sid: sid})
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		req.AddCookie(&http.Cookie{Name: "ACCESS_TOKEN", Value: token})
+	}
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, req)
+	return rr
+}
+
+func (s *testServer) createInvite(body string) types.InviteDto {
+	s.t.Helper()
+	rr := s.do(http.MethodPost, "/admin/invites", types.UTypeAdmin, body)
+	if rr.Code != http.StatusCreated {
+		s.t.Fatalf("expected status code %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body)
+	}
+	return decode[types.InviteDto](s.t, rr)
+}
+
+func decode[T any](t *testing.T, rr *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.NewDecoder(rr.Body).Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func tokenOf(t *testing.T, link string) string {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Query().Get("token")
+}
+
+func acceptBody(token string) string {
+	return `{"token":"` + token + `","firstName":"Rita","lastName":"Recruiter","password":"pass@1234"}`
+}
+
+func expectProblem(t *testing.T, rr *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	if rr.Code != status {
+		t.Fatalf("expected status code %d, got %d: %s", status, rr.Code, rr.Body)
+	}
+	if p := decode[utils.Problem](t, rr); p.Code != code {
+		t.Errorf("expected code %s, got %s", code, p.Code)
+	}
+}
+
+func TestInviteLifecycle(t *testing.T) {
+	t.Parallel()
+	s := newTestServer(t)
+
+	inv := s.createInvite(`{"email":" Rita@Acme.com ","uType":"recruiter","company":"Acme"}`)
+	if inv.Email != "rita@acme.com" || inv.UType != types.UTypeRecruiter || inv.Company != "Acme" ||
+		inv.Status != types.InviteStatusPending || inv.Link == "" {
+		t.Fatalf("unexpected invite %+v", inv)
+	}
+	if !strings.HasPrefix(inv.Link, config.Default().Registration.InviteUrl+"?token=") {
+		t.Errorf("expected the link to point at the invite url, got %s", inv.Link)
+	}
+
+	rr := s.do(http.MethodPost, "/invites/accept", "", acceptBody(tokenOf(t, inv.Link)))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body)
+	}
+	u, err := s.users.GetUserByEmail(context.Background(), "rita@acme.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.UType != types.UTypeRecruiter || u.Company != "Acme" || u.FirstName != "Rita" {
+		t.Errorf("expected a recruiter of Acme, got %+v", u)
+	}
+
+	// links work once
+	rr = s.do(http.MethodPost, "/invites/accept", "", acceptBody(tokenOf(t, inv.Link)))
+	expectProblem(t, rr, http.StatusGone, utils.CodeInviteClosed)
+
+	rr = s.do(http.MethodGet, "/admin/invites?status=accepted", types.UTypeAdmin, "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+	list := decode[[]types.InviteDto](t, rr)
+	if len(list) != 1 || list[0].Id != inv.Id || list[0].AcceptedUserId != u.Id || list[0].Link != "" {
+		t.Errorf("expected the accepted invite without a link, got %+v", list)
+	}
+}
+
+func TestResendInvalidatesEarlierLinks(t *testing.T) {
+	t.Parallel()
+	s := newTestServer(t)
+	inv := s.createInvite(`{"email":"otto@college.edu","uType":"officer"}`)
+
+	// expiry has second precision, wait for the next one so the renewed
+	// expiry differs
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	rr := s.do(http.MethodPost, "/admin/invites/"+strconv.Itoa(inv.Id)+"/resend", types.UTypeAdmin, "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	renewed := decode[types.InviteDto](t, rr)
+	if renewed.SendCount != 2 || renewed.Link == inv.Link {
+		t.Fatalf("expected a resent invite with a new link, got %+v", renewed)
+	}
+
+	rr = s.do(http.MethodPost, "/invites/accept", "", acceptBody(tokenOf(t, inv.Link)))
+	expectProblem(t, rr, http.StatusGone, utils.CodeInviteClosed)
+
+	rr = s.do(http.MethodPost, "/invites/accept", "", acceptBody(tokenOf(t, renewed.Link)))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body)
+	}
+	if u := decode[types.User](t, rr); u.UType != types.UTypeOfficer {
+		t.Errorf("expected an officer, got %+v", u)
+	}
+}
+
+func TestRevokedInviteCannotBeAccepted(t *testing.T) {
+	t.Parallel()
+	s := newTestServer(t)
+	inv := s.createInvite(`{"email":"rita@acme.com","uType":"recruiter","company":"Acme"}`)
+
+	rr := s.do(http.MethodDelete, "/admin/invites/"+strconv.Itoa(inv.Id), types.UTypeAdmin, "")
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusAccepted, rr.Code, rr.Body)
+	}
+
+	rr = s.do(http.MethodPost, "/invites/accept", "", acceptBody(tokenOf(t, inv.Link)))
+	expectProblem(t, rr, http.StatusGone, utils.CodeInviteClosed)
+
+	rr = s.do(http.MethodDelete, "/admin/invites/"+strconv.Itoa(inv.Id), types.UTypeAdmin, "")
+	expectProblem(t, rr, http.StatusGone, utils.CodeInviteClosed)
+	rr = s.do(http.MethodPost, "/admin/invites/"+strconv.Itoa(inv.Id)+"/resend", types.UTypeAdmin, "")
+	expectProblem(t, rr, http.StatusGone, utils.CodeInviteClosed)
+}
+
+func TestInviteErrors(t *testing.T) {
+	t.Parallel()
+	s := newTestServer(t)
+	if _, err := s.users.CreateUser(context.Background(), types.User{Email: "taken@acme.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		role   string
+		body   string
+		status int
+		code   string
+	}{
+		{"students cannot invite", http.MethodPost, "/admin/invites", types.UTypeStudent, `{"email":"a@acme.com","uType":"recruiter","company":"Acme"}`, http.StatusForbidden, utils.CodeForbidden},
+		{"officers cannot list", http.MethodGet, "/admin/invites", types.UTypeOfficer, "", http.StatusForbidden, utils.CodeForbidden},
+		{"admins cannot be invited", http.MethodPost, "/admin/invites", types.UTypeAdmin, `{"email":"a@acme.com","uType":"admin"}`, http.StatusBadRequest, utils.CodeValidationFailed},
+		{"recruiters need a company", http.MethodPost, "/admin/invites", types.UTypeAdmin, `{"email":"a@acme.com","uType":"recruiter"}`, http.StatusBadRequest, utils.CodeValidationFailed},
+		{"existing users cannot be invited", http.MethodPost, "/admin/invites", types.UTypeAdmin, `{"email":"Taken@acme.com","uType":"recruiter","company":"Acme"}`, http.StatusConflict, utils.CodeEmailTaken},
+		{"unknown invite", http.MethodPost, "/admin/invites/99/resend", types.UTypeAdmin, "", http.StatusNotFound, utils.CodeInviteNotFound},
+		{"unknown status", http.MethodGet, "/admin/invites?status=lost", types.UTypeAdmin, "", http.StatusBadRequest, utils.CodeInvalidQuery},
+		{"forged token", http.MethodPost, "/invites/accept", "", acceptBody("not-a-token"), http.StatusBadRequest, utils.CodeInviteInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := s.do(tt.method, tt.path, tt.role, tt.body)
+			expectProblem(t, rr, tt.status, tt.code)
+		})
+	}
+}
+
+func TestAcceptForTakenEmail(t *testing.T) {
+	t.Parallel()
+	s := newTestServer(t)
+	inv := s.createInvite(`{"email":"rita@acme.com","uType":"recruiter","company":"Acme"}`)
+
+	// the address registered on its own after the invite was sent
+	if _, err := s.users.CreateUser(context.Background(), types.User{Email: "rita@acme.com"}); err != nil {
+		t.Fatal(err)
+	}
+	rr := s.do(http.MethodPost, "/invites/accept", "", acceptBody(tokenOf(t, inv.Link)))
+	expectProblem(t, rr, http.StatusConflict, utils.CodeEmailTaken)
+}
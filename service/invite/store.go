@@ -0,0 +1,173 @@
+package invite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/db"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/jackc/pgx/v5"
+)
+
+const inviteColumns = "id, email, uType, company, createdBy, sendCount, lastSentAt, expiresAt, acceptedAt, coalesce(acceptedUserId, 0), revokedAt, createdAt"
+
+// pending matches invites that can still be accepted, apart from expiry
+const pending = "acceptedAt is null and revokedAt is null"
+
+var statusConds = map[string]string{
+	types.InviteStatusPending:  pending + " and expiresAt > now()",
+	types.InviteStatusExpired:  pending + " and expiresAt <= now()",
+	types.InviteStatusAccepted: "acceptedAt is not null",
+	types.InviteStatusRevoked:  "revokedAt is not null",
+}
+
+type Store struct {
+	db db.Querier
+}
+
+func NewStore(db db.Querier) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+func (s *Store) CreateInvite(ctx context.Context, inv types.Invite) (int, error) {
+	var id int
+	err := s.db.QueryRow(ctx,
+		"insert into invites (email, uType, company, createdBy, expiresAt) values ($1, $2, $3, $4, $5) returning id",
+		inv.Email, inv.UType, inv.Company, inv.CreatedBy, inv.ExpiresAt,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *Store) GetInvite(ctx context.Context, id int) (*types.Invite, error) {
+	inv, err := scanInvite(s.db.QueryRow(ctx, "select "+inviteColumns+" from invites where id = $1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (s *Store) ListInvites(ctx context.Context, filter types.InviteFilter) ([]types.Invite, error) {
+	var conds []string
+	var args []any
+	if filter.Status != "" {
+		cond, ok := statusConds[filter.Status]
+		if !ok {
+			return nil, fmt.Errorf("unknown invite status %s", filter.Status)
+		}
+		conds = append(conds, cond)
+	}
+	if filter.Email != "" {
+		args = append(args, filter.Email)
+		conds = append(conds, fmt.Sprintf("lower(email) = lower($%d)", len(args)))
+	}
+
+	query := "select " + inviteColumns + " from invites"
+	if len(conds) > 0 {
+		query += " where " + strings.Join(conds, " and ")
+	}
+	query += " order by id desc"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" limit $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" offset $%d", len(args))
+	}
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []types.Invite{}
+	for rows.Next() {
+		inv, err := scanInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, *inv)
+	}
+	return invites, rows.Err()
+}
+
+func scanInvite(row pgx.Row) (*types.Invite, error) {
+	inv := new(types.Invite)
+	err := row.Scan(
+		&inv.Id,
+		&inv.Email,
+		&inv.UType,
+		&inv.Company,
+		&inv.CreatedBy,
+		&inv.SendCount,
+		&inv.LastSentAt,
+		&inv.ExpiresAt,
+		&inv.AcceptedAt,
+		&inv.AcceptedUserId,
+		&inv.RevokedAt,
+		&inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (s *Store) RenewInvite(ctx context.Context, id int, expiresAt time.Time) error {
+	tag, err := s.db.Exec(ctx,
+		"update invites set expiresAt = $2, sendCount = sendCount + 1, lastSentAt = now() where id = $1 and "+pending,
+		id, expiresAt,
+	)
+	if err != nil {
+		return err
+	}
+	return s.checkUpdated(ctx, id, tag.RowsAffected())
+}
+
+func (s *Store) RevokeInvite(ctx context.Context, id int) error {
+	tag, err := s.db.Exec(ctx, "update invites set revokedAt = now() where id = $1 and "+pending, id)
+	if err != nil {
+		return err
+	}
+	return s.checkUpdated(ctx, id, tag.RowsAffected())
+}
+
+func (s *Store) AcceptInvite(ctx context.Context, id int, userId int) error {
+	tag, err := s.db.Exec(ctx,
+		"update invites set acceptedAt = now(), acceptedUserId = $2 where id = $1 and expiresAt > now() and "+pending,
+		id, userId,
+	)
+	if err != nil {
+		return err
+	}
+	return s.checkUpdated(ctx, id, tag.RowsAffected())
+}
+
+// checkUpdated tells a missing invite apart from one that was not pending
+// when an update only applies to pending invites.
+func (s *Store) checkUpdated(ctx context.Context, id int, updated int64) error {
+	if updated > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := s.db.QueryRow(ctx, "select exists(select 1 from invites where id = $1)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return types.ErrNotFound
+	}
+	return types.ErrInviteClosed
+}
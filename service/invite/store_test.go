@@ -0,0 +1,27 @@
+package invite
+
+import (
+	"testing"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/user"
+	"github.com/SufyaanKhateeb/college-placement-app-api/storetest"
+	"github.com/SufyaanKhateeb/college-placement-app-api/testdb"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+func TestMain(m *testing.M) {
+	testdb.Main(m)
+}
+
+func TestStore(t *testing.T) {
+	storetest.InviteStore(t, func(t *testing.T) (types.InviteStore, types.UserStore) {
+		pool := testdb.New(t)
+		return NewStore(pool), user.NewStore(pool)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	storetest.InviteStore(t, func(t *testing.T) (types.InviteStore, types.UserStore) {
+		return NewMemoryStore(), user.NewMemoryStore()
+	})
+}
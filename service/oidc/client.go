@@ -0,0 +1,303 @@
+// Package oidc is a minimal OpenID Connect relying party: discovery, the
+// authorization code flow with PKCE and ID token verification against the
+// provider's published keys.
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// keyRefreshInterval limits how often an unknown key id refetches the
+	// key set, so tokens with made up key ids can't hammer the provider
+	keyRefreshInterval = time.Minute
+	maxResponseBytes   = 1 << 20
+)
+
+var signingMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// Client talks to a single provider. Discovery happens on first use rather
+// than at startup, so an unreachable provider only breaks single sign-on.
+type Client struct {
+	cfg  config.OIDCConfig
+	http *http.Client
+
+	mu       sync.Mutex
+	metadata *metadata
+	keys     map[string]any
+	keysAt   time.Time
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+func NewClient(cfg config.OIDCConfig, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Client{
+		cfg:  cfg,
+		http: httpClient,
+	}
+}
+
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	m, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(m.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("parsing authorization endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", c.cfg.ClientId)
+	q.Set("redirect_uri", c.cfg.RedirectUrl)
+	q.Set("scope", strings.Join(c.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", CodeChallenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// CodeChallenge derives the S256 PKCE challenge sent in place of verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (c *Client) Exchange(ctx context.Context, code, verifier, nonce string) (*types.OIDCIdentity, error) {
+	m, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.cfg.RedirectUrl},
+		"client_id":     {c.cfg.ClientId},
+		"code_verifier": {verifier},
+	}
+	if c.cfg.ClientSecret != "" {
+		form.Set("client_secret", c.cfg.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tokens struct {
+		IdToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := c.do(req, &tokens)
+	if err != nil {
+		return nil, fmt.Errorf("exchanging code: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("exchanging code: status %d: %s %s", status, tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IdToken == "" {
+		return nil, errors.New("exchanging code: no id_token in response")
+	}
+
+	return c.verify(ctx, m, tokens.IdToken, nonce)
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce           string   `json:"nonce"`
+	AuthorizedParty string   `json:"azp"`
+	Email           string   `json:"email"`
+	EmailVerified   flexBool `json:"email_verified"`
+	GivenName       string   `json:"given_name"`
+	FamilyName      string   `json:"family_name"`
+	Name            string   `json:"name"`
+}
+
+func (c *Client) verify(ctx context.Context, m *metadata, raw, nonce string) (*types.OIDCIdentity, error) {
+	var claims idTokenClaims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return c.key(ctx, m, kid)
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(m.Issuer),
+		jwt.WithAudience(c.cfg.ClientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("verifying id token: %w", err)
+	}
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, errors.New("verifying id token: nonce mismatch")
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != c.cfg.ClientId {
+		return nil, errors.New("verifying id token: issued to another party")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("verifying id token: no subject")
+	}
+
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(strings.TrimSpace(claims.Name), " ")
+	}
+	return &types.OIDCIdentity{
+		Issuer:        m.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		FirstName:     firstName,
+		LastName:      lastName,
+	}, nil
+}
+
+// discover fetches the provider metadata once, a failed attempt is retried
+// on the next login.
+func (c *Client) discover(ctx context.Context) (*metadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.metadata != nil {
+		return c.metadata, nil
+	}
+
+	var m metadata
+	if err := c.get(ctx, strings.TrimSuffix(c.cfg.Issuer, "/")+"/.well-known/openid-configuration", &m); err != nil {
+		return nil, fmt.Errorf("discovering provider: %w", err)
+	}
+	// the issuer in tokens is compared against this value, it must be the one
+	// we were configured with
+	if m.Issuer != c.cfg.Issuer {
+		return nil, fmt.Errorf("discovering provider: issuer %q does not match %q", m.Issuer, c.cfg.Issuer)
+	}
+	if m.AuthorizationEndpoint == "" || m.TokenEndpoint == "" || m.JwksUri == "" {
+		return nil, errors.New("discovering provider: metadata is missing endpoints")
+	}
+	c.metadata = &m
+	return c.metadata, nil
+}
+
+// key returns the public key with kid. Providers rotate their keys, so an
+// unknown kid refetches the key set.
+func (c *Client) key(ctx context.Context, m *metadata, kid string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if k, ok := lookupKey(c.keys, kid); ok {
+		return k, nil
+	}
+	if time.Since(c.keysAt) < keyRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set jwks
+	if err := c.get(ctx, m.JwksUri, &set); err != nil {
+		return nil, fmt.Errorf("fetching signing keys: %w", err)
+	}
+	keys, err := set.publicKeys()
+	if err != nil {
+		return nil, fmt.Errorf("fetching signing keys: %w", err)
+	}
+	c.keys, c.keysAt = keys, time.Now()
+
+	if k, ok := lookupKey(c.keys, kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey falls back to the only key for tokens without a kid.
+func lookupKey(keys map[string]any, kid string) (any, bool) {
+	if k, ok := keys[kid]; ok {
+		return k, true
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, k := range keys {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+func (c *Client) get(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	status, err := c.do(req, v)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, status)
+	}
+	return nil
+}
+
+// do decodes any JSON response body into v, error responses of the token
+// endpoint carry their reason in the body.
+func (c *Client) do(req *http.Request, v any) (int, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return 0, err
+	}
+	if err := json.Unmarshal(body, v); err != nil && resp.StatusCode == http.StatusOK {
+		return 0, fmt.Errorf("decoding response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// flexBool accepts "true" as well as true, some providers send
+// email_verified as a string.
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true", `"true"`:
+		*b = true
+	case "false", `"false"`, "null":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package oidc
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/oidc/oidctest"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+const (
+	testVerifier = "a-verifier-that-is-long-enough-for-pkce-0123456789"
+	testNonce    = "nonce-1"
+)
+
+func newTestClient(t *testing.T) (*Client, *oidctest.Provider) {
+	t.Helper()
+	provider := oidctest.New(t, "placement-app")
+	client := NewClient(config.OIDCConfig{
+		Enabled:     true,
+		Issuer:      provider.Issuer(),
+		ClientId:    "placement-app",
+		RedirectUrl: "http://localhost:8090/api/v1/oidc/callback",
+		Scopes:      []string{"openid", "email", "profile"},
+	}, provider.Client())
+	return client, provider
+}
+
+// login runs the flow up to the code exchange with the given verifier.
+func login(t *testing.T, client *Client, provider *oidctest.Provider, verifier string) (*types.OIDCIdentity, error) {
+	t.Helper()
+	ctx := context.Background()
+	authURL, err := client.AuthCodeURL(ctx, "state-1", testNonce, testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := provider.Authorize(t, authURL)
+	if state != "state-1" {
+		t.Fatalf("expected state to round trip, got %q", state)
+	}
+	return client.Exchange(ctx, code, verifier, testNonce)
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	t.Parallel()
+	client, provider := newTestClient(t)
+
+	authURL, err := client.AuthCodeURL(context.Background(), "state-1", testNonce, testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authURL, provider.URL+"/authorize?") {
+		t.Errorf("expected the discovered authorization endpoint, got %s", authURL)
+	}
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "placement-app",
+		"redirect_uri":          "http://localhost:8090/api/v1/oidc/callback",
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 testNonce,
+		"code_challenge":        CodeChallenge(testVerifier),
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if got := u.Query().Get(k); got != v {
+			t.Errorf("expected %s=%q, got %q", k, v, got)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	t.Parallel()
+	client, provider := newTestClient(t)
+
+	id, err := login(t, client, provider, testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := types.OIDCIdentity{
+		Issuer:        provider.Issuer(),
+		Subject:       "student-1",
+		Email:         "student@college.edu",
+		EmailVerified: true,
+		FirstName:     "Stu",
+		LastName:      "Dent",
+	}
+	if *id != want {
+		t.Errorf("expected %+v, got %+v", want, *id)
+	}
+
+	t.Run("falls back to name and string email_verified", func(t *testing.T) {
+		provider.SetClaims(map[string]any{"given_name": nil, "family_name": nil, "name": "Ada Lovelace", "email_verified": "true"})
+		t.Cleanup(func() {
+			provider.SetClaims(map[string]any{"given_name": "Stu", "family_name": "Dent", "name": nil, "email_verified": true})
+		})
+
+		id, err := login(t, client, provider, testVerifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id.FirstName != "Ada" || id.LastName != "Lovelace" || !id.EmailVerified {
+			t.Errorf("unexpected identity %+v", id)
+		}
+	})
+}
+
+func TestExchangeRejects(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		claims   map[string]any
+		verifier string
+		want     string
+	}{
+		{name: "wrong verifier", verifier: "another-verifier-that-is-long-enough-0123456789", want: "PKCE"},
+		{name: "wrong nonce", claims: map[string]any{"nonce": "replayed"}, want: "nonce"},
+		{name: "missing nonce", claims: map[string]any{"nonce": ""}, want: "nonce"},
+		{name: "other audience", claims: map[string]any{"aud": "another-app"}, want: "audience"},
+		{name: "other authorized party", claims: map[string]any{"aud": []string{"placement-app", "another-app"}, "azp": "another-app"}, want: "another party"},
+		{name: "other issuer", claims: map[string]any{"iss": "https://evil.example.com"}, want: "issuer"},
+		{name: "expired", claims: map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}, want: "expired"},
+		{name: "no expiry", claims: map[string]any{"exp": nil}, want: "exp"},
+		{name: "no subject", claims: map[string]any{"sub": nil}, want: "subject"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, provider := newTestClient(t)
+			provider.SetClaims(tt.claims)
+			verifier := testVerifier
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+
+			id, err := login(t, client, provider, verifier)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error mentioning %q, got %+v (%v)", tt.want, id, err)
+			}
+		})
+	}
+}
+
+func TestExchangeCodeIsSingleUse(t *testing.T) {
+	t.Parallel()
+	client, provider := newTestClient(t)
+	ctx := context.Background()
+
+	authURL, err := client.AuthCodeURL(ctx, "state-1", testNonce, testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ := provider.Authorize(t, authURL)
+	if _, err := client.Exchange(ctx, code, testVerifier, testNonce); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Exchange(ctx, code, testVerifier, testNonce); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("expected the second exchange to fail, got %v", err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	t.Parallel()
+	client, provider := newTestClient(t)
+
+	if _, err := login(t, client, provider, testVerifier); err != nil {
+		t.Fatal(err)
+	}
+
+	// the key set was just fetched, an unknown kid doesn't refetch it yet
+	provider.RotateKey(t)
+	if _, err := login(t, client, provider, testVerifier); err == nil || !strings.Contains(err.Error(), "unknown signing key") {
+		t.Fatalf("expected an unknown key error, got %v", err)
+	}
+
+	client.mu.Lock()
+	client.keysAt = time.Now().Add(-keyRefreshInterval)
+	client.mu.Unlock()
+	if _, err := login(t, client, provider, testVerifier); err != nil {
+		t.Errorf("expected the rotated key to be fetched, got %v", err)
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	t.Parallel()
+	provider := oidctest.New(t, "placement-app")
+	client := NewClient(config.OIDCConfig{
+		Issuer:   provider.Issuer() + "/",
+		ClientId: "placement-app",
+	}, provider.Client())
+
+	_, err := client.AuthCodeURL(context.Background(), "state-1", testNonce, testVerifier)
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected an issuer mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+// Package oidctest runs a stub OpenID provider for tests. It approves every
+// authorization request without a login page and signs ID tokens with a key
+// it publishes in its JWKS.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Provider struct {
+	*httptest.Server
+	ClientId string
+
+	mu     sync.Mutex
+	key    *rsa.PrivateKey
+	kid    int
+	claims jwt.MapClaims
+	codes  map[string]authRequest
+}
+
+type authRequest struct {
+	redirectUri string
+	challenge   string
+	nonce       string
+}
+
+// New starts a provider that issues tokens for clientId, closed when the test
+// ends. ID tokens default to a verified student, see SetClaims.
+func New(t *testing.T, clientId string) *Provider {
+	t.Helper()
+	p := &Provider{
+		ClientId: clientId,
+		claims: jwt.MapClaims{
+			"sub":            "student-1",
+			"email":          "student@college.edu",
+			"email_verified": true,
+			"given_name":     "Stu",
+			"family_name":    "Dent",
+		},
+		codes: map[string]authRequest{},
+	}
+	p.RotateKey(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.handleDiscovery)
+	mux.HandleFunc("GET /jwks", p.handleJwks)
+	mux.HandleFunc("GET /authorize", p.handleAuthorize)
+	mux.HandleFunc("POST /token", p.handleToken)
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *Provider) Issuer() string {
+	return p.URL
+}
+
+// SetClaims merges claims into every ID token issued from now on, a nil
+// value removes the claim. Overriding iss, aud, exp or nonce lets tests
+// issue tokens the client must reject.
+func (p *Provider) SetClaims(claims map[string]any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for k, v := range claims {
+		p.claims[k] = v
+	}
+}
+
+// RotateKey replaces the signing key, tokens are signed with a new kid.
+func (p *Provider) RotateKey(t *testing.T) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.key = key
+	p.kid++
+}
+
+// Authorize follows authURL like a browser would and returns the code and
+// state the provider redirects back with.
+func (p *Provider) Authorize(t *testing.T, authURL string) (code, state string) {
+	t.Helper()
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("expected authorize to redirect, got %d", resp.StatusCode)
+	}
+
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc.Query().Get("code"), loc.Query().Get("state")
+}
+
+func (p *Provider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, map[string]any{
+		"issuer":                                p.URL,
+		"authorization_endpoint":                p.URL + "/authorize",
+		"token_endpoint":                        p.URL + "/token",
+		"jwks_uri":                              p.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *Provider) handleJwks(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	pub := p.key.PublicKey
+	kid := strconv.Itoa(p.kid)
+	p.mu.Unlock()
+
+	writeJson(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": kid,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *Provider) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != p.ClientId || q.Get("redirect_uri") == "" ||
+		q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = authRequest{
+		redirectUri: q.Get("redirect_uri"),
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+	}
+	p.mu.Unlock()
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	rq := redirect.Query()
+	rq.Set("code", code)
+	rq.Set("state", q.Get("state"))
+	redirect.RawQuery = rq.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *Provider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request", err.Error())
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	code := r.PostForm.Get("code")
+	req, ok := p.codes[code]
+	delete(p.codes, code) // codes are single use
+	switch {
+	case r.PostForm.Get("grant_type") != "authorization_code":
+		tokenError(w, "unsupported_grant_type", "")
+		return
+	case !ok:
+		tokenError(w, "invalid_grant", "unknown or used code")
+		return
+	case r.PostForm.Get("client_id") != p.ClientId || r.PostForm.Get("redirect_uri") != req.redirectUri:
+		tokenError(w, "invalid_grant", "client or redirect_uri mismatch")
+		return
+	case challenge(r.PostForm.Get("code_verifier")) != req.challenge:
+		tokenError(w, "invalid_grant", "PKCE verification failed")
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   p.URL,
+		"aud":   p.ClientId,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": req.nonce,
+	}
+	for k, v := range p.claims {
+		if v == nil {
+			delete(claims, k)
+			continue
+		}
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = strconv.Itoa(p.kid)
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		tokenError(w, "server_error", err.Error())
+		return
+	}
+
+	writeJson(w, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func tokenError(w http.ResponseWriter, code, description string) {
+	writeJson(w, http.StatusBadRequest, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+func writeJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,77 @@
+package placement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// CalendarSource puts the application deadlines and pre-placement talks of
+// the drives a student is eligible for, and their booked interviews, in
+// their calendar. Other roles get no placement events.
+type CalendarSource struct {
+	Store types.PlacementStore
+	Users types.UserStore
+}
+
+func NewCalendarSource(store types.PlacementStore, users types.UserStore) *CalendarSource {
+	return &CalendarSource{
+		Store: store,
+		Users: users,
+	}
+}
+
+func (c *CalendarSource) UserEvents(ctx context.Context, userId int) ([]types.CalendarEvent, error) {
+	u, err := c.Users.GetUserById(ctx, userId)
+	// feeds of deleted users are left empty rather than failing
+	if errors.Is(err, types.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.UType != types.UTypeStudent {
+		return nil, nil
+	}
+
+	drives, err := c.Store.ListEligibleDrives(ctx, u.Branch)
+	if err != nil {
+		return nil, err
+	}
+	interviews, err := c.Store.ListInterviews(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []types.CalendarEvent{}
+	for _, d := range drives {
+		events = append(events, types.CalendarEvent{
+			Uid:      fmt.Sprintf("deadline-%d@placement-app", d.Id),
+			Category: "deadline",
+			Summary:  fmt.Sprintf("Application deadline: %s, %s", d.CompanyName, d.Title),
+			Start:    d.ApplicationDeadline,
+		})
+		if d.TalkAt != nil {
+			events = append(events, types.CalendarEvent{
+				Uid:      fmt.Sprintf("talk-%d@placement-app", d.Id),
+				Category: "talk",
+				Summary:  fmt.Sprintf("Pre-placement talk: %s, %s", d.CompanyName, d.Title),
+				Location: d.TalkLocation,
+				Start:    *d.TalkAt,
+			})
+		}
+	}
+	for _, i := range interviews {
+		events = append(events, types.CalendarEvent{
+			Uid:      fmt.Sprintf("interview-%d@placement-app", i.Id),
+			Category: "interview",
+			Summary:  fmt.Sprintf("Interview: %s, %s", i.CompanyName, i.DriveTitle),
+			Location: i.Location,
+			Start:    i.StartsAt,
+			End:      i.EndsAt,
+		})
+	}
+	return events, nil
+}
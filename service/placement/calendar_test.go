@@ -0,0 +1,70 @@
+package placement
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/user"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+func TestCalendarSource(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := NewMemoryStore()
+	users := user.NewMemoryStore()
+	source := NewCalendarSource(store, users)
+
+	newUser := func(email, uType, branch string) int {
+		id, err := users.CreateUser(ctx, types.User{Email: email, UType: uType, Branch: branch})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	cse := newUser("cse@example.com", types.UTypeStudent, "CSE")
+	mech := newUser("mech@example.com", types.UTypeStudent, "MECH")
+	recruiter := newUser("recruiter@example.com", types.UTypeRecruiter, "")
+
+	companyId, err := store.CreateCompany(ctx, types.Company{Name: "Acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Date(2024, 9, 25, 18, 30, 0, 0, time.UTC)
+	for _, d := range []types.Drive{
+		{CompanyId: companyId, Title: "SDE", EligibleBranches: []string{"CSE"}, ApplicationDeadline: deadline},
+		{CompanyId: companyId, Title: "GET", ApplicationDeadline: deadline.Add(24 * time.Hour)},
+	} {
+		if _, err := store.CreateDrive(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		userId int
+		want   []string
+	}{
+		{"student sees drives of their branch", cse, []string{"deadline-1@placement-app", "deadline-2@placement-app"}},
+		{"student doesn't see other branches", mech, []string{"deadline-2@placement-app"}},
+		{"other roles get no events", recruiter, nil},
+		{"missing users get no events", 1000, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := source.UserEvents(ctx, tt.userId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range events {
+				got = append(got, e.Uid)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package placement
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// MemoryStore is an in-memory types.PlacementStore for tests and demo mode.
+// It doesn't see the users, so rows of missing students are accepted.
+type MemoryStore struct {
+	mu           sync.Mutex
+	companies    map[int]types.Company
+	drives       map[int]types.Drive
+	applications map[int]types.Application
+	slots        map[int]types.InterviewSlot
+	offers       map[int]types.Offer
+	profiles     map[int]types.StudentProfile
+	lastIds      map[string]int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		companies:    map[int]types.Company{},
+		drives:       map[int]types.Drive{},
+		applications: map[int]types.Application{},
+		slots:        map[int]types.InterviewSlot{},
+		offers:       map[int]types.Offer{},
+		profiles:     map[int]types.StudentProfile{},
+		lastIds:      map[string]int{},
+	}
+}
+
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// nextId counts ids per table like a serial column, callers hold mu.
+func (s *MemoryStore) nextId(table string) int {
+	s.lastIds[table]++
+	return s.lastIds[table]
+}
+
+func (s *MemoryStore) CreateCompany(ctx context.Context, c types.Company) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c.Id = s.nextId("companies")
+	c.CreatedAt = now()
+	s.companies[c.Id] = c
+	return c.Id, nil
+}
+
+func (s *MemoryStore) GetCompanyByDomain(ctx context.Context, domain string) (*types.Company, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found *types.Company
+	for _, c := range s.companies {
+		if strings.EqualFold(c.Domain, domain) && (found == nil || c.Id < found.Id) {
+			found = &c
+		}
+	}
+	if found == nil {
+		return nil, types.ErrNotFound
+	}
+	return found, nil
+}
+
+func (s *MemoryStore) CreateDrive(ctx context.Context, d types.Drive) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.companies[d.CompanyId]; !ok {
+		return 0, types.ErrNotFound
+	}
+	d.Id = s.nextId("drives")
+	d.CompanyName = ""
+	d.EligibleBranches = slices.Clone(d.EligibleBranches)
+	if d.EligibleBranches == nil {
+		d.EligibleBranches = []string{}
+	}
+	d.CreatedAt = now()
+	s.drives[d.Id] = d
+	return d.Id, nil
+}
+
+func (s *MemoryStore) CreateApplication(ctx context.Context, a types.Application) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.drives[a.DriveId]; !ok {
+		return 0, types.ErrNotFound
+	}
+	for _, other := range s.applications {
+		if other.DriveId == a.DriveId && other.StudentId == a.StudentId {
+			return 0, types.ErrAlreadyApplied
+		}
+	}
+	a.Id = s.nextId("applications")
+	if a.Status == "" {
+		a.Status = types.ApplicationStatusApplied
+	}
+	a.CreatedAt = now()
+	a.UpdatedAt = a.CreatedAt
+	s.applications[a.Id] = a
+	return a.Id, nil
+}
+
+func (s *MemoryStore) SetApplicationStatus(ctx context.Context, id int, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.applications[id]
+	if !ok {
+		return types.ErrNotFound
+	}
+	a.Status = status
+	a.UpdatedAt = now()
+	s.applications[id] = a
+	return nil
+}
+
+func (s *MemoryStore) ListApplications(ctx context.Context, studentId int) ([]types.Application, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	applications := []types.Application{}
+	for _, a := range s.applications {
+		if a.StudentId == studentId {
+			applications = append(applications, a)
+		}
+	}
+	slices.SortFunc(applications, func(a, b types.Application) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+	return applications, nil
+}
+
+func (s *MemoryStore) CreateInterviewSlot(ctx context.Context, slot types.InterviewSlot) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.applications[slot.ApplicationId]; !ok {
+		return 0, types.ErrNotFound
+	}
+	slot.Id = s.nextId("slots")
+	s.slots[slot.Id] = slot
+	return slot.Id, nil
+}
+
+func (s *MemoryStore) CreateOffer(ctx context.Context, o types.Offer) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.applications[o.ApplicationId]; !ok {
+		return 0, types.ErrNotFound
+	}
+	o.Id = s.nextId("offers")
+	if o.Status == "" {
+		o.Status = types.OfferStatusExtended
+	}
+	o.CreatedAt = now()
+	s.offers[o.Id] = o
+	return o.Id, nil
+}
+
+func (s *MemoryStore) ListOffers(ctx context.Context, studentId int) ([]types.Offer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offers := []types.Offer{}
+	for _, o := range s.offers {
+		if s.applications[o.ApplicationId].StudentId == studentId {
+			offers = append(offers, o)
+		}
+	}
+	slices.SortFunc(offers, func(a, b types.Offer) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+	return offers, nil
+}
+
+func (s *MemoryStore) UpsertStudentProfile(ctx context.Context, p types.StudentProfile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p.UpdatedAt = now()
+	s.profiles[p.UserId] = p
+	return nil
+}
+
+func (s *MemoryStore) GetStudentProfile(ctx context.Context, userId int) (*types.StudentProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.profiles[userId]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (s *MemoryStore) ListEligibleDrives(ctx context.Context, branch string) ([]types.Drive, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	drives := []types.Drive{}
+	for _, d := range s.drives {
+		eligible := len(d.EligibleBranches) == 0 || slices.ContainsFunc(d.EligibleBranches, func(b string) bool {
+			return strings.EqualFold(b, branch)
+		})
+		if !eligible {
+			continue
+		}
+		d.CompanyName = s.companies[d.CompanyId].Name
+		d.EligibleBranches = slices.Clone(d.EligibleBranches)
+		drives = append(drives, d)
+	}
+
+	slices.SortFunc(drives, func(a, b types.Drive) int {
+		return cmp.Or(a.ApplicationDeadline.Compare(b.ApplicationDeadline), cmp.Compare(a.Id, b.Id))
+	})
+	return drives, nil
+}
+
+func (s *MemoryStore) ListInterviews(ctx context.Context, studentId int) ([]types.Interview, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	interviews := []types.Interview{}
+	for _, slot := range s.slots {
+		a := s.applications[slot.ApplicationId]
+		if a.StudentId != studentId {
+			continue
+		}
+		d := s.drives[a.DriveId]
+		interviews = append(interviews, types.Interview{
+			InterviewSlot: slot,
+			DriveId:       d.Id,
+			DriveTitle:    d.Title,
+			CompanyName:   s.companies[d.CompanyId].Name,
+		})
+	}
+
+	slices.SortFunc(interviews, func(a, b types.Interview) int {
+		return cmp.Or(a.StartsAt.Compare(b.StartsAt), cmp.Compare(a.Id, b.Id))
+	})
+	return interviews, nil
+}
+
+// Snapshot returns a function that puts the store back as it is now, memtx
+// rolls back failed units of work with it.
+func (s *MemoryStore) Snapshot() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	companies, drives, applications := maps.Clone(s.companies), maps.Clone(s.drives), maps.Clone(s.applications)
+	slots, offers, profiles, lastIds := maps.Clone(s.slots), maps.Clone(s.offers), maps.Clone(s.profiles), maps.Clone(s.lastIds)
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.companies, s.drives, s.applications = companies, drives, applications
+		s.slots, s.offers, s.profiles, s.lastIds = slots, offers, profiles, lastIds
+	}
+}
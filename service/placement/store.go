@@ -0,0 +1,266 @@
+package placement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/db"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const driveColumns = "d.id, d.companyId, c.name, d.title, d.eligibleBranches, d.applicationDeadline, d.talkAt, d.talkLocation, d.createdAt"
+
+// uniqueViolation is the Postgres error code for a broken unique constraint,
+// on applications the only one is a student applying to a drive twice
+const uniqueViolation = "23505"
+
+// foreignKeyViolation is returned when a row points at a missing one
+const foreignKeyViolation = "23503"
+
+type Store struct {
+	db db.Querier
+}
+
+func NewStore(db db.Querier) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+func (s *Store) CreateCompany(ctx context.Context, c types.Company) (int, error) {
+	var id int
+	err := s.db.QueryRow(ctx, "insert into companies (name, domain) values ($1, $2) returning id", c.Name, c.Domain).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("creating company: %w", err)
+	}
+	return id, nil
+}
+
+func (s *Store) GetCompanyByDomain(ctx context.Context, domain string) (*types.Company, error) {
+	c := new(types.Company)
+	err := s.db.QueryRow(ctx, "select id, name, domain, createdAt from companies where lower(domain) = lower($1) order by id limit 1", domain).
+		Scan(&c.Id, &c.Name, &c.Domain, &c.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting company by domain: %w", err)
+	}
+	return c, nil
+}
+
+func (s *Store) CreateDrive(ctx context.Context, d types.Drive) (int, error) {
+	branches := d.EligibleBranches
+	if branches == nil {
+		branches = []string{}
+	}
+
+	var id int
+	err := s.db.QueryRow(ctx,
+		"insert into drives (companyId, title, eligibleBranches, applicationDeadline, talkAt, talkLocation) values ($1, $2, $3, $4, $5, $6) returning id",
+		d.CompanyId, d.Title, branches, d.ApplicationDeadline, d.TalkAt, d.TalkLocation,
+	).Scan(&id)
+	if err != nil {
+		return 0, insertError("drive", err)
+	}
+	return id, nil
+}
+
+func (s *Store) CreateApplication(ctx context.Context, a types.Application) (int, error) {
+	status := a.Status
+	if status == "" {
+		status = types.ApplicationStatusApplied
+	}
+
+	var id int
+	err := s.db.QueryRow(ctx,
+		"insert into applications (driveId, studentId, status) values ($1, $2, $3) returning id",
+		a.DriveId, a.StudentId, status,
+	).Scan(&id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return 0, types.ErrAlreadyApplied
+	}
+	if err != nil {
+		return 0, insertError("application", err)
+	}
+	return id, nil
+}
+
+func (s *Store) SetApplicationStatus(ctx context.Context, id int, status string) error {
+	tag, err := s.db.Exec(ctx, "update applications set status = $2, updatedAt = now() where id = $1", id, status)
+	if err != nil {
+		return fmt.Errorf("updating application %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) ListApplications(ctx context.Context, studentId int) ([]types.Application, error) {
+	rows, err := s.db.Query(ctx,
+		"select id, driveId, studentId, status, createdAt, updatedAt from applications where studentId = $1 order by id",
+		studentId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing applications of student %d: %w", studentId, err)
+	}
+	defer rows.Close()
+
+	applications := []types.Application{}
+	for rows.Next() {
+		var a types.Application
+		if err := rows.Scan(&a.Id, &a.DriveId, &a.StudentId, &a.Status, &a.CreatedAt, &a.UpdatedAt); err != nil {
+			return nil, err
+		}
+		applications = append(applications, a)
+	}
+	return applications, rows.Err()
+}
+
+func (s *Store) CreateInterviewSlot(ctx context.Context, slot types.InterviewSlot) (int, error) {
+	var id int
+	err := s.db.QueryRow(ctx,
+		"insert into interview_slots (applicationId, startsAt, endsAt, location) values ($1, $2, $3, $4) returning id",
+		slot.ApplicationId, slot.StartsAt, slot.EndsAt, slot.Location,
+	).Scan(&id)
+	if err != nil {
+		return 0, insertError("interview slot", err)
+	}
+	return id, nil
+}
+
+func (s *Store) ListEligibleDrives(ctx context.Context, branch string) ([]types.Drive, error) {
+	rows, err := s.db.Query(ctx,
+		"select "+driveColumns+" from drives d join companies c on c.id = d.companyId"+
+			" where cardinality(d.eligibleBranches) = 0 or lower($1) = any(select lower(b) from unnest(d.eligibleBranches) b)"+
+			" order by d.applicationDeadline, d.id",
+		branch,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing drives: %w", err)
+	}
+	defer rows.Close()
+
+	drives := []types.Drive{}
+	for rows.Next() {
+		var d types.Drive
+		err := rows.Scan(
+			&d.Id,
+			&d.CompanyId,
+			&d.CompanyName,
+			&d.Title,
+			&d.EligibleBranches,
+			&d.ApplicationDeadline,
+			&d.TalkAt,
+			&d.TalkLocation,
+			&d.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		drives = append(drives, d)
+	}
+	return drives, rows.Err()
+}
+
+func (s *Store) ListInterviews(ctx context.Context, studentId int) ([]types.Interview, error) {
+	rows, err := s.db.Query(ctx,
+		"select i.id, i.applicationId, i.startsAt, i.endsAt, i.location, d.id, d.title, c.name"+
+			" from interview_slots i join applications a on a.id = i.applicationId"+
+			" join drives d on d.id = a.driveId join companies c on c.id = d.companyId"+
+			" where a.studentId = $1 order by i.startsAt, i.id",
+		studentId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing interviews of student %d: %w", studentId, err)
+	}
+	defer rows.Close()
+
+	interviews := []types.Interview{}
+	for rows.Next() {
+		var i types.Interview
+		err := rows.Scan(&i.Id, &i.ApplicationId, &i.StartsAt, &i.EndsAt, &i.Location, &i.DriveId, &i.DriveTitle, &i.CompanyName)
+		if err != nil {
+			return nil, err
+		}
+		interviews = append(interviews, i)
+	}
+	return interviews, rows.Err()
+}
+
+func (s *Store) CreateOffer(ctx context.Context, o types.Offer) (int, error) {
+	status := o.Status
+	if status == "" {
+		status = types.OfferStatusExtended
+	}
+
+	var id int
+	err := s.db.QueryRow(ctx,
+		"insert into offers (applicationId, ctc, status) values ($1, $2, $3) returning id",
+		o.ApplicationId, o.Ctc, status,
+	).Scan(&id)
+	if err != nil {
+		return 0, insertError("offer", err)
+	}
+	return id, nil
+}
+
+func (s *Store) ListOffers(ctx context.Context, studentId int) ([]types.Offer, error) {
+	rows, err := s.db.Query(ctx,
+		"select o.id, o.applicationId, o.ctc, o.status, o.createdAt from offers o join applications a on a.id = o.applicationId"+
+			" where a.studentId = $1 order by o.id",
+		studentId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing offers of student %d: %w", studentId, err)
+	}
+	defer rows.Close()
+
+	offers := []types.Offer{}
+	for rows.Next() {
+		var o types.Offer
+		if err := rows.Scan(&o.Id, &o.ApplicationId, &o.Ctc, &o.Status, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		offers = append(offers, o)
+	}
+	return offers, rows.Err()
+}
+
+func (s *Store) UpsertStudentProfile(ctx context.Context, p types.StudentProfile) error {
+	_, err := s.db.Exec(ctx,
+		"insert into student_profiles (userId, cgpa, graduationYear) values ($1, $2, $3)"+
+			" on conflict (userId) do update set cgpa = excluded.cgpa, graduationYear = excluded.graduationYear, updatedAt = now()",
+		p.UserId, p.Cgpa, p.GraduationYear,
+	)
+	if err != nil {
+		return insertError("student profile", err)
+	}
+	return nil
+}
+
+func (s *Store) GetStudentProfile(ctx context.Context, userId int) (*types.StudentProfile, error) {
+	p := new(types.StudentProfile)
+	err := s.db.QueryRow(ctx, "select userId, cgpa, graduationYear, updatedAt from student_profiles where userId = $1", userId).
+		Scan(&p.UserId, &p.Cgpa, &p.GraduationYear, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting profile of student %d: %w", userId, err)
+	}
+	return p, nil
+}
+
+func insertError(what string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return types.ErrNotFound
+	}
+	return fmt.Errorf("creating %s: %w", what, err)
+}
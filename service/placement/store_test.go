@@ -0,0 +1,27 @@
+package placement
+
+import (
+	"testing"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/user"
+	"github.com/SufyaanKhateeb/college-placement-app-api/storetest"
+	"github.com/SufyaanKhateeb/college-placement-app-api/testdb"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+func TestMain(m *testing.M) {
+	testdb.Main(m)
+}
+
+func TestStore(t *testing.T) {
+	storetest.PlacementStore(t, func(t *testing.T) (types.PlacementStore, types.UserStore) {
+		pool := testdb.New(t)
+		return NewStore(pool), user.NewStore(pool)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	storetest.PlacementStore(t, func(t *testing.T) (types.PlacementStore, types.UserStore) {
+		return NewMemoryStore(), user.NewMemoryStore()
+	})
+}
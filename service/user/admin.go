@@ -0,0 +1,222 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/middlewares"
+	"github.com/SufyaanKhateeb/college-placement-app-api/reqctx"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+func (h *Handler) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r.URL.Query())
+	if err != nil {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInvalidQuery, err.Error()))
+		return
+	}
+
+	users, err := h.Store.SearchUsers(r.Context(), filter)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	utils.WriteJson(w, http.StatusOK, users)
+}
+
+func (h *Handler) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInvalidId, "invalid user id"))
+		return
+	}
+
+	u, err := h.Store.GetUserById(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	utils.WriteJson(w, http.StatusOK, u)
+}
+
+func (h *Handler) handleDisable(w http.ResponseWriter, r *http.Request) {
+	if u, ok := h.updateUser(w, r, true, func(users types.UserStore, id int) error {
+		return users.SetUserDisabled(r.Context(), id, true)
+	}); ok {
+		utils.WriteJson(w, http.StatusOK, u)
+	}
+}
+
+func (h *Handler) handleEnable(w http.ResponseWriter, r *http.Request) {
+	if u, ok := h.updateUser(w, r, false, func(users types.UserStore, id int) error {
+		return users.SetUserDisabled(r.Context(), id, false)
+	}); ok {
+		utils.WriteJson(w, http.StatusOK, u)
+	}
+}
+
+func (h *Handler) handleUpdateRole(w http.ResponseWriter, r *http.Request) {
+	var payload types.UpdateRolePayload
+	if err := utils.ParseJson(r, &payload); err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.GetValidator().Struct(payload); err != nil {
+		utils.WriteProblem(w, utils.ValidationProblem(err))
+		return
+	}
+	// only recruiters belong to a company
+	if payload.UType != types.UTypeRecruiter {
+		payload.Company = ""
+	}
+
+	if u, ok := h.updateUser(w, r, true, func(users types.UserStore, id int) error {
+		return users.SetUserRole(r.Context(), id, payload.UType, payload.Company)
+	}); ok {
+		utils.WriteJson(w, http.StatusOK, u)
+	}
+}
+
+// handleResetPassword signs the user out and locks their current password.
+// They pick a new one through the returned link, which the admin passes on as
+// there is no mail delivery.
+func (h *Handler) handleResetPassword(w http.ResponseWriter, r *http.Request) {
+	u, ok := h.updateUser(w, r, false, func(users types.UserStore, id int) error {
+		return users.RequirePasswordChange(r.Context(), id)
+	})
+	if !ok {
+		return
+	}
+
+	// the expiry is truncated to seconds, the precision of the token
+	expiresAt := time.Now().Add(h.Auth.PasswordResetTTL).Truncate(time.Second)
+	token, err := h.Signer.SignPasswordReset(u.Id, *u.PasswordResetAt, expiresAt)
+	if err != nil {
+		utils.WriteInternalError(w, r, "signing password reset failed", err)
+		return
+	}
+	link, err := url.Parse(h.Auth.PasswordResetUrl)
+	if err != nil {
+		utils.WriteInternalError(w, r, "building password reset link failed", err)
+		return
+	}
+	q := link.Query()
+	q.Set("token", token)
+	link.RawQuery = q.Encode()
+
+	utils.WriteJson(w, http.StatusOK, types.PasswordResetDto{
+		User:      *u,
+		Link:      link.String(),
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (h *Handler) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	if u, ok := h.updateUser(w, r, true, func(users types.UserStore, id int) error {
+		return users.DeleteUser(r.Context(), id)
+	}); ok {
+		utils.WriteJson(w, http.StatusAccepted, u)
+	}
+}
+
+// updateUser applies update to the user in the URL and signs them out
+// everywhere in the same transaction, so AuthMiddleware refuses their current
+// tokens from the next request on and they log in again with the new state.
+// notSelf stops admins from locking themselves out. It returns the updated
+// user, and false once it has written an error response.
+func (h *Handler) updateUser(w http.ResponseWriter, r *http.Request, notSelf bool, update func(users types.UserStore, id int) error) (*types.User, bool) {
+	ctxUser := reqctx.MustUser(r.Context())
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInvalidId, "invalid user id"))
+		return nil, false
+	}
+	if notSelf && id == ctxUser.Id {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusConflict, utils.CodeConflict, "admins can't do this to their own account"))
+		return nil, false
+	}
+
+	var before, after *types.User
+	err = h.Tx.InTx(r.Context(), func(s types.Stores) error {
+		var err error
+		if before, err = s.User.GetUserById(r.Context(), id); err != nil {
+			return err
+		}
+		if err := update(s.User, id); err != nil {
+			return err
+		}
+		if err := s.Auth.RevokeSessions(r.Context(), id); err != nil {
+			return err
+		}
+
+		// a deleted user can't be read back and is audited as nil
+		after, err = s.User.GetUserById(r.Context(), id)
+		if errors.Is(err, types.ErrNotFound) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		writeStoreError(w, r, err)
+		return nil, false
+	}
+	middlewares.SetAuditTarget(r, "user", strconv.Itoa(id), before, after)
+
+	reqctx.Logger(r.Context()).Info("user updated by admin", "target_user_id", id)
+	return after, true
+}
+
+func parseFilter(q url.Values) (types.UserFilter, error) {
+	filter := types.UserFilter{
+		Query:  strings.TrimSpace(q.Get("q")),
+		UType:  q.Get("role"),
+		Branch: strings.TrimSpace(q.Get("branch")),
+		Status: q.Get("status"),
+		Sort:   q.Get("sort"),
+		Limit:  defaultPageSize,
+	}
+	var err error
+
+	roles := []string{types.UTypeStudent, types.UTypeRecruiter, types.UTypeOfficer, types.UTypeAdmin}
+	if filter.UType != "" && !slices.Contains(roles, filter.UType) {
+		return filter, fmt.Errorf("invalid role %s", filter.UType)
+	}
+	statuses := []string{types.UserStatusActive, types.UserStatusDisabled, types.UserStatusDeleted}
+	if filter.Status != "" && !slices.Contains(statuses, filter.Status) {
+		return filter, fmt.Errorf("invalid status %s", filter.Status)
+	}
+	sorts := []string{types.UserSortCreatedAt, types.UserSortName, types.UserSortEmail}
+	if field := strings.TrimPrefix(filter.Sort, "-"); filter.Sort != "" && !slices.Contains(sorts, field) {
+		return filter, fmt.Errorf("invalid sort %s", filter.Sort)
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit <= 0 {
+			return filter, fmt.Errorf("invalid limit %s", v)
+		}
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
+	}
+	if v := q.Get("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil || filter.Offset < 0 {
+			return filter, fmt.Errorf("invalid offset %s", v)
+		}
+	}
+
+	return filter, nil
+}
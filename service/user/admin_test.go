@@ -0,0 +1,273 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/auth"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type adminTestServer struct {
+	t      *testing.T
+	router *chi.Mux
+	store  *MemoryStore
+}
+
+func newAdminTestServer(t *testing.T) *adminTestServer {
+	t.Helper()
+	store := NewMemoryStore()
+	handler := newHandler(t, store, config.Default(), nil)
+	router := chi.NewRouter()
+	handler.RegisterRoutes(router)
+	return &adminTestServer{t: t, router: router, store: store}
+}
+
+func (s *adminTestServer) do(method, path, body string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	s.t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, req)
+	return rr
+}
+
+// createUser stores a user with the password pass@123 and logs them in.
+func (s *adminTestServer) createUser(email, uType, branch string) (int, []*http.Cookie) {
+	s.t.Helper()
+	hash, err := auth.HashPassword(context.Background(), "pass@123")
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	id, err := s.store.CreateUser(context.Background(), types.User{FirstName: "f", LastName: "l", Email: email, Password: hash, UType: uType, Branch: branch})
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	rr := s.do(http.MethodPost, "/login", `{"email":"`+email+`","password":"pass@123"}`, nil)
+	if rr.Code != http.StatusOK {
+		s.t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	return id, rr.Result().Cookies()
+}
+
+func decodeUsers(t *testing.T, rr *httptest.ResponseRecorder) []types.User {
+	t.Helper()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	var users []types.User
+	if err := json.NewDecoder(rr.Body).Decode(&users); err != nil {
+		t.Fatal(err)
+	}
+	return users
+}
+
+func TestAdminUsersRequireAdmin(t *testing.T) {
+	t.Parallel()
+	s := newAdminTestServer(t)
+	_, officer := s.createUser("officer@college.edu", types.UTypeOfficer, "")
+
+	if rr := s.do(http.MethodGet, "/admin/users", "", nil); rr.Code != http.StatusFound {
+		t.Errorf("expected a redirect to login, got %d", rr.Code)
+	}
+	if rr := s.do(http.MethodGet, "/admin/users", "", officer); rr.Code != http.StatusForbidden {
+		t.Errorf("expected status code %d, got %d", http.StatusForbidden, rr.Code)
+	}
+}
+
+func TestAdminListUsers(t *testing.T) {
+	t.Parallel()
+	s := newAdminTestServer(t)
+	_, admin := s.createUser("admin@college.edu", types.UTypeAdmin, "")
+	cse, _ := s.createUser("cse@college.edu", types.UTypeStudent, "CSE")
+	s.createUser("ece@college.edu", types.UTypeStudent, "ECE")
+
+	users := decodeUsers(t, s.do(http.MethodGet, "/admin/users?role=student&branch=cse", "", admin))
+	if len(users) != 1 || users[0].Id != cse || users[0].Branch != "CSE" {
+		t.Errorf("expected the cse student, got %+v", users)
+	}
+	users = decodeUsers(t, s.do(http.MethodGet, "/admin/users?q=college&sort=email&limit=2&offset=1", "", admin))
+	if len(users) != 2 || users[0].Email != "cse@college.edu" || users[1].Email != "ece@college.edu" {
+		t.Errorf("expected the second page by email, got %+v", users)
+	}
+	if strings.Contains(s.do(http.MethodGet, "/admin/users", "", admin).Body.String(), "$2a$") {
+		t.Error("expected no password hashes in the response")
+	}
+
+	for _, q := range []string{"role=root", "status=gone", "sort=password", "sort=-", "limit=0", "offset=-1"} {
+		expectProblem(t, s.do(http.MethodGet, "/admin/users?"+q, "", admin), http.StatusBadRequest, utils.CodeInvalidQuery)
+	}
+}
+
+func TestAdminDisableUser(t *testing.T) {
+	t.Parallel()
+	s := newAdminTestServer(t)
+	adminId, admin := s.createUser("admin@college.edu", types.UTypeAdmin, "")
+	id, student := s.createUser("s@college.edu", types.UTypeStudent, "")
+	path := "/admin/users/" + strconv.Itoa(id)
+
+	rr := s.do(http.MethodPost, path+"/disable", "", admin)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	// the student is signed out at once and can't log back in
+	if rr := s.do(http.MethodGet, "/user", "", student); rr.Code != http.StatusFound {
+		t.Errorf("expected the student to be signed out, got %d", rr.Code)
+	}
+	expectProblem(t, s.do(http.MethodPost, "/login", `{"email":"s@college.edu","password":"pass@123"}`, nil), http.StatusForbidden, utils.CodeAccountDisabled)
+	if users := decodeUsers(t, s.do(http.MethodGet, "/admin/users?status=disabled", "", admin)); len(users) != 1 || users[0].Id != id {
+		t.Errorf("expected the student to be listed as disabled, got %+v", users)
+	}
+
+	if rr := s.do(http.MethodPost, path+"/enable", "", admin); rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	if rr := s.do(http.MethodPost, "/login", `{"email":"s@college.edu","password":"pass@123"}`, nil); rr.Code != http.StatusOK {
+		t.Errorf("expected the student to log in again, got %d: %s", rr.Code, rr.Body)
+	}
+
+	expectProblem(t, s.do(http.MethodPost, "/admin/users/"+strconv.Itoa(adminId)+"/disable", "", admin), http.StatusConflict, utils.CodeConflict)
+	expectProblem(t, s.do(http.MethodPost, "/admin/users/12345/disable", "", admin), http.StatusNotFound, utils.CodeUserNotFound)
+	expectProblem(t, s.do(http.MethodPost, "/admin/users/abc/disable", "", admin), http.StatusBadRequest, utils.CodeInvalidId)
+
+	// sessions are checked against the user too, in case they outlive the
+	// revocation
+	otherId, other := s.createUser("o@college.edu", types.UTypeStudent, "")
+	if err := s.store.SetUserDisabled(context.Background(), otherId, true); err != nil {
+		t.Fatal(err)
+	}
+	if rr := s.do(http.MethodGet, "/user", "", other); rr.Code != http.StatusFound {
+		t.Errorf("expected a disabled user's session to be refused, got %d", rr.Code)
+	}
+}
+
+func TestAdminUpdateRole(t *testing.T) {
+	t.Parallel()
+	s := newAdminTestServer(t)
+	adminId, admin := s.createUser("admin@college.edu", types.UTypeAdmin, "")
+	id, student := s.createUser("s@college.edu", types.UTypeStudent, "")
+	path := "/admin/users/" + strconv.Itoa(id) + "/role"
+
+	expectProblem(t, s.do(http.MethodPut, path, `{"uType":"recruiter"}`, admin), http.StatusBadRequest, utils.CodeValidationFailed)
+
+	rr := s.do(http.MethodPut, path, `{"uType":"recruiter","company":"Acme"}`, admin)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	var u types.User
+	if err := json.NewDecoder(rr.Body).Decode(&u); err != nil {
+		t.Fatal(err)
+	}
+	if u.UType != types.UTypeRecruiter || u.Company != "Acme" {
+		t.Errorf("expected an Acme recruiter, got %+v", u)
+	}
+	// the old tokens still carry the student role
+	if rr := s.do(http.MethodGet, "/user", "", student); rr.Code != http.StatusFound {
+		t.Errorf("expected the user to be signed out, got %d", rr.Code)
+	}
+
+	if rr := s.do(http.MethodPut, path, `{"uType":"officer","company":"Acme"}`, admin); rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	if u, _ := s.store.GetUserById(context.Background(), id); u.Company != "" {
+		t.Errorf("expected officers to have no company, got %+v", u)
+	}
+
+	expectProblem(t, s.do(http.MethodPut, "/admin/users/"+strconv.Itoa(adminId)+"/role", `{"uType":"student"}`, admin), http.StatusConflict, utils.CodeConflict)
+}
+
+func TestAdminResetPassword(t *testing.T) {
+	t.Parallel()
+	s := newAdminTestServer(t)
+	_, admin := s.createUser("admin@college.edu", types.UTypeAdmin, "")
+	id, student := s.createUser("s@college.edu", types.UTypeStudent, "")
+
+	reset := func() string {
+		t.Helper()
+		rr := s.do(http.MethodPost, "/admin/users/"+strconv.Itoa(id)+"/password-reset", "", admin)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+		}
+		var dto types.PasswordResetDto
+		if err := json.NewDecoder(rr.Body).Decode(&dto); err != nil {
+			t.Fatal(err)
+		}
+		if !dto.PasswordChangeRequired || dto.ExpiresAt.IsZero() {
+			t.Errorf("unexpected reset %+v", dto)
+		}
+		prefix := config.Default().Auth.PasswordResetUrl + "?token="
+		if !strings.HasPrefix(dto.Link, prefix) {
+			t.Fatalf("expected a link to %s, got %s", prefix, dto.Link)
+		}
+		return strings.TrimPrefix(dto.Link, prefix)
+	}
+	complete := func(token, password string) *httptest.ResponseRecorder {
+		return s.do(http.MethodPost, "/password/reset", `{"token":"`+token+`","newPassword":"`+password+`"}`, nil)
+	}
+
+	replaced := reset()
+	if rr := s.do(http.MethodGet, "/user", "", student); rr.Code != http.StatusFound {
+		t.Errorf("expected the student to be signed out, got %d", rr.Code)
+	}
+	expectProblem(t, s.do(http.MethodPost, "/login", `{"email":"s@college.edu","password":"pass@123"}`, nil), http.StatusForbidden, utils.CodePasswordChangeNeeded)
+	// the old password may be what got the account reset
+	expectProblem(t, s.do(http.MethodPost, "/password", `{"email":"s@college.edu","password":"pass@123","newPassword":"new@12345"}`, nil), http.StatusForbidden, utils.CodePasswordChangeNeeded)
+
+	token := reset()
+	expectProblem(t, complete(replaced, "new@12345"), http.StatusBadRequest, utils.CodePasswordResetInvalid)
+	expectProblem(t, complete("not-a-token", "new@12345"), http.StatusBadRequest, utils.CodePasswordResetInvalid)
+	expectProblem(t, complete(token, "short"), http.StatusBadRequest, utils.CodeValidationFailed)
+
+	rr := complete(token, "new@12345")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	if rr := s.do(http.MethodGet, "/user", "", rr.Result().Cookies()); rr.Code != http.StatusOK {
+		t.Errorf("expected the new password to sign the student in, got %d", rr.Code)
+	}
+	expectProblem(t, complete(token, "other@12345"), http.StatusBadRequest, utils.CodePasswordResetInvalid)
+	if rr := s.do(http.MethodPost, "/login", `{"email":"s@college.edu","password":"new@12345"}`, nil); rr.Code != http.StatusOK {
+		t.Errorf("expected a normal login with the new password, got %d: %s", rr.Code, rr.Body)
+	}
+
+	// a regular change still takes the current password
+	expectProblem(t, s.do(http.MethodPost, "/password", `{"email":"s@college.edu","password":"wrong@123","newPassword":"other@12345"}`, nil), http.StatusBadRequest, utils.CodeInvalidCredentials)
+	if rr := s.do(http.MethodPost, "/password", `{"email":"s@college.edu","password":"new@12345","newPassword":"other@12345"}`, nil); rr.Code != http.StatusOK {
+		t.Errorf("expected the password to change, got %d: %s", rr.Code, rr.Body)
+	}
+}
+
+func TestAdminDeleteUser(t *testing.T) {
+	t.Parallel()
+	s := newAdminTestServer(t)
+	adminId, admin := s.createUser("admin@college.edu", types.UTypeAdmin, "")
+	id, student := s.createUser("s@college.edu", types.UTypeStudent, "")
+	path := "/admin/users/" + strconv.Itoa(id)
+
+	if rr := s.do(http.MethodDelete, path, "", admin); rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusAccepted, rr.Code, rr.Body)
+	}
+	if rr := s.do(http.MethodGet, "/user", "", student); rr.Code != http.StatusFound {
+		t.Errorf("expected the student to be signed out, got %d", rr.Code)
+	}
+	// a deleted user is not found
+	expectProblem(t, s.do(http.MethodGet, path, "", admin), http.StatusNotFound, utils.CodeUserNotFound)
+	if users := decodeUsers(t, s.do(http.MethodGet, "/admin/users?status=deleted", "", admin)); len(users) != 1 || users[0].Id != id || users[0].DeletedAt == nil {
+		t.Errorf("expected the student to be listed as deleted, got %+v", users)
+	}
+	// deleting twice finds no user
+	expectProblem(t, s.do(http.MethodDelete, path, "", admin), http.StatusNotFound, utils.CodeUserNotFound)
+
+	expectProblem(t, s.do(http.MethodDelete, "/admin/users/"+strconv.Itoa(adminId), "", admin), http.StatusConflict, utils.CodeConflict)
+}
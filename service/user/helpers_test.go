@@ -0,0 +1,26 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/auth"
+	"github.com/SufyaanKhateeb/college-placement-app-api/stores/memtx"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// newHandler returns a handler on store with a real auth service. Sessions
+// live next to the users as if both were in one database, and cfg gets fresh
+// keys unless it has some.
+func newHandler(t *testing.T, store types.UserStore, cfg config.Config, provider types.OIDCProvider) *Handler {
+	t.Helper()
+	if cfg.Auth.PrivateKey == nil {
+		if err := cfg.Auth.GenerateKeys(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sessions := auth.NewMemoryStore(store)
+	tx := memtx.New(types.Stores{User: store, Auth: sessions})
+	authService := auth.NewAuthService(sessions, cfg.Auth)
+	return NewHandler(store, tx, authService, authService, cfg.Auth, cfg.Cookie, cfg.Registration, cfg.OIDC, provider)
+}
@@ -0,0 +1,247 @@
+package user
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// MemoryStore is an in-memory types.UserStore for tests and demo mode. It
+// passes the same conformance suite as Store.
+type MemoryStore struct {
+	mu      sync.Mutex
+	users   map[int]types.User
+	byEmail map[string]int // keyed by lowercased email, like the unique index
+	links   map[identity]int
+	nextId  int
+}
+
+type identity struct {
+	issuer, subject string
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:   map[int]types.User{},
+		byEmail: map[string]int{},
+		links:   map[identity]int{},
+		nextId:  1,
+	}
+}
+
+func (s *MemoryStore) CheckUserWithEmailExits(ctx context.Context, email string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.byEmail[strings.ToLower(email)]
+	return ok, nil
+}
+
+func (s *MemoryStore) GetUserByEmail(ctx context.Context, email string) (*types.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.byEmail[strings.ToLower(email)]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	return s.get(id)
+}
+
+func (s *MemoryStore) GetUserById(ctx context.Context, id int) (*types.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.get(id)
+}
+
+// get returns the user unless it is missing or deleted, callers hold mu.
+func (s *MemoryStore) get(id int) (*types.User, error) {
+	u, ok := s.users[id]
+	if !ok || u.DeletedAt != nil {
+		return nil, types.ErrNotFound
+	}
+	return &u, nil
+}
+
+func (s *MemoryStore) CreateUser(ctx context.Context, u types.User) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.byEmail[strings.ToLower(u.Email)]; ok {
+		return 0, types.ErrDuplicateEmail
+	}
+	if u.UType == "" {
+		u.UType = types.UTypeStudent
+	}
+	u.DisabledAt, u.DeletedAt, u.PasswordChangeRequired, u.PasswordResetAt = nil, nil, false, nil
+
+	u.Id = s.nextId
+	u.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	s.nextId++
+
+	s.users[u.Id] = u
+	s.byEmail[strings.ToLower(u.Email)] = u.Id
+	return u.Id, nil
+}
+
+func (s *MemoryStore) GetUserByIdentity(ctx context.Context, issuer, subject string) (*types.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.links[identity{issuer, subject}]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	return s.get(id)
+}
+
+func (s *MemoryStore) LinkIdentity(ctx context.Context, userId int, issuer, subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userId]; !ok {
+		return types.ErrNotFound
+	}
+	key := identity{issuer, subject}
+	if _, ok := s.links[key]; !ok {
+		s.links[key] = userId
+	}
+	return nil
+}
+
+func (s *MemoryStore) SearchUsers(ctx context.Context, filter types.UserFilter) ([]types.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := map[string]func(u types.User) bool{
+		"":                       func(u types.User) bool { return u.DeletedAt == nil },
+		types.UserStatusActive:   func(u types.User) bool { return u.DeletedAt == nil && u.DisabledAt == nil },
+		types.UserStatusDisabled: func(u types.User) bool { return u.DeletedAt == nil && u.DisabledAt != nil },
+		types.UserStatusDeleted:  func(u types.User) bool { return u.DeletedAt != nil },
+	}
+	matchStatus, ok := status[filter.Status]
+	if !ok {
+		return nil, fmt.Errorf("unknown user status %s", filter.Status)
+	}
+
+	field, desc := strings.CutPrefix(filter.Sort, "-")
+	if field == "" {
+		field, desc = types.UserSortCreatedAt, true
+	}
+	sorts := map[string]func(a, b types.User) int{
+		types.UserSortCreatedAt: func(a, b types.User) int { return a.CreatedAt.Compare(b.CreatedAt) },
+		types.UserSortName: func(a, b types.User) int {
+			return cmp.Or(
+				cmp.Compare(strings.ToLower(a.FirstName), strings.ToLower(b.FirstName)),
+				cmp.Compare(strings.ToLower(a.LastName), strings.ToLower(b.LastName)),
+			)
+		},
+		types.UserSortEmail: func(a, b types.User) int { return cmp.Compare(strings.ToLower(a.Email), strings.ToLower(b.Email)) },
+	}
+	compare, ok := sorts[field]
+	if !ok {
+		return nil, fmt.Errorf("unknown user sort %s", filter.Sort)
+	}
+
+	query := strings.ToLower(filter.Query)
+	users := []types.User{}
+	for _, u := range s.users {
+		if !matchStatus(u) ||
+			query != "" && !strings.Contains(strings.ToLower(u.FirstName+" "+u.LastName), query) && !strings.Contains(strings.ToLower(u.Email), query) ||
+			filter.UType != "" && u.UType != filter.UType ||
+			filter.Branch != "" && !strings.EqualFold(u.Branch, filter.Branch) {
+			continue
+		}
+		users = append(users, u)
+	}
+	slices.SortFunc(users, func(a, b types.User) int {
+		c := cmp.Or(compare(a, b), cmp.Compare(a.Id, b.Id))
+		if desc {
+			return -c
+		}
+		return c
+	})
+
+	users = users[min(filter.Offset, len(users)):]
+	if filter.Limit > 0 && len(users) > filter.Limit {
+		users = users[:filter.Limit]
+	}
+	return users, nil
+}
+
+func (s *MemoryStore) SetUserDisabled(ctx context.Context, id int, disabled bool) error {
+	return s.update(id, func(u *types.User) {
+		switch {
+		case !disabled:
+			u.DisabledAt = nil
+		case u.DisabledAt == nil:
+			now := time.Now().UTC().Truncate(time.Microsecond)
+			u.DisabledAt = &now
+		}
+	})
+}
+
+func (s *MemoryStore) SetUserRole(ctx context.Context, id int, uType, company string) error {
+	return s.update(id, func(u *types.User) {
+		u.UType = uType
+		u.Company = company
+	})
+}
+
+func (s *MemoryStore) RequirePasswordChange(ctx context.Context, id int) error {
+	return s.update(id, func(u *types.User) {
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		u.PasswordChangeRequired = true
+		u.PasswordResetAt = &now
+	})
+}
+
+func (s *MemoryStore) UpdatePassword(ctx context.Context, id int, hash string) error {
+	return s.update(id, func(u *types.User) {
+		u.Password = hash
+		u.PasswordChangeRequired = false
+		u.PasswordResetAt = nil
+	})
+}
+
+func (s *MemoryStore) DeleteUser(ctx context.Context, id int) error {
+	return s.update(id, func(u *types.User) {
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		u.DeletedAt = &now
+	})
+}
+
+func (s *MemoryStore) update(id int, fn func(u *types.User)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	fn(u)
+	s.users[id] = *u
+	return nil
+}
+
+// Snapshot returns a function that puts the store back as it is now, memtx
+// rolls back failed units of work with it.
+func (s *MemoryStore) Snapshot() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, byEmail, links, nextId := maps.Clone(s.users), maps.Clone(s.byEmail), maps.Clone(s.links), s.nextId
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.users, s.byEmail, s.links, s.nextId = users, byEmail, links, nextId
+	}
+}
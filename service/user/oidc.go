@@ -0,0 +1,202 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/metrics"
+	"github.com/SufyaanKhateeb/college-placement-app-api/middlewares"
+	"github.com/SufyaanKhateeb/college-placement-app-api/reqctx"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
+)
+
+// flowCookie carries state, nonce and PKCE verifier from the redirect to the
+// provider until it redirects back to the callback.
+const (
+	flowCookie = "OIDC_FLOW"
+	flowTTL    = 10 * time.Minute
+)
+
+var (
+	errEmailUnverified  = errors.New("identity provider did not share a verified email")
+	errDomainNotAllowed = errors.New("email domain not allowed for students")
+	errNotLinkable      = errors.New("only student accounts are linked by email")
+)
+
+func (h *Handler) handleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	var flow [3]string // state, nonce, verifier
+	for i := range flow {
+		token, err := randomToken()
+		if err != nil {
+			utils.WriteInternalError(w, r, "generating login flow failed", err)
+			return
+		}
+		flow[i] = token
+	}
+
+	authURL, err := h.Provider.AuthCodeURL(r.Context(), flow[0], flow[1], flow[2])
+	if err != nil {
+		reqctx.Logger(r.Context()).Error("identity provider unavailable", "err", err)
+		utils.WriteProblem(w, utils.NewProblem(http.StatusServiceUnavailable, utils.CodeUnavailable, "the identity provider is unavailable"))
+		return
+	}
+
+	h.writeFlowCookie(w, strings.Join(flow[:], "."), flowTTL)
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+func (h *Handler) handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(flowCookie)
+	// the flow is single use, whatever happens next
+	h.writeFlowCookie(w, "", 0)
+	if err != nil {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeLoginStateInvalid, "the login has expired, please start again"))
+		return
+	}
+	flow := strings.Split(cookie.Value, ".")
+	q := r.URL.Query()
+	if len(flow) != 3 || subtle.ConstantTimeCompare([]byte(flow[0]), []byte(q.Get("state"))) != 1 {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeLoginStateInvalid, "the login has expired, please start again"))
+		return
+	}
+
+	if q.Get("error") != "" {
+		reqctx.Logger(r.Context()).Info("identity provider refused login", "error", q.Get("error"), "description", q.Get("error_description"))
+		utils.WriteProblem(w, utils.NewProblem(http.StatusUnauthorized, utils.CodeSSOFailed, "the identity provider refused the login"))
+		return
+	}
+
+	identity, err := h.Provider.Exchange(r.Context(), q.Get("code"), flow[2], flow[1])
+	if err != nil {
+		metrics.Logins.WithLabelValues("failure").Inc()
+		reqctx.Logger(r.Context()).Warn("single sign-on failed", "err", err)
+		utils.WriteProblem(w, utils.NewProblem(http.StatusUnauthorized, utils.CodeSSOFailed, "single sign-on failed"))
+		return
+	}
+
+	u, err := h.oidcUser(r.Context(), identity)
+	switch {
+	case errors.Is(err, errEmailUnverified):
+		metrics.Logins.WithLabelValues("failure").Inc()
+		utils.WriteProblem(w, utils.NewProblem(http.StatusForbidden, utils.CodeEmailUnverified, "your account at the identity provider has no verified email address"))
+		return
+	case errors.Is(err, errDomainNotAllowed):
+		metrics.Logins.WithLabelValues("failure").Inc()
+		utils.WriteProblem(w, utils.NewProblem(http.StatusForbidden, utils.CodeEmailDomainNotAllowed, "students must sign in with their college email address"))
+		return
+	case errors.Is(err, errNotLinkable):
+		metrics.Logins.WithLabelValues("failure").Inc()
+		utils.WriteProblem(w, utils.NewProblem(http.StatusForbidden, utils.CodeSSONotLinked, "your account is not linked to single sign-on, log in with your password"))
+		return
+	case err != nil:
+		writeStoreError(w, r, err)
+		return
+	}
+	middlewares.SetAuditTarget(r, "user", strconv.Itoa(u.Id), nil, map[string]any{"issuer": identity.Issuer, "subject": identity.Subject})
+	if u.DisabledAt != nil {
+		metrics.Logins.WithLabelValues("failure").Inc()
+		writeDisabled(w)
+		return
+	}
+	// an admin reset means the account may be compromised, which the
+	// provider knows nothing about
+	if u.PasswordChangeRequired {
+		writePasswordChangeRequired(w)
+		return
+	}
+
+	if err := h.signIn(w, r, u); err != nil {
+		utils.WriteInternalError(w, r, "error signing in", err)
+		return
+	}
+
+	metrics.Logins.WithLabelValues("success").Inc()
+	reqctx.Logger(r.Context()).Info("user logged in with single sign-on", "user_id", u.Id)
+	http.Redirect(w, r, h.OIDC.PostLoginUrl, http.StatusFound)
+}
+
+// oidcUser finds the user behind an external identity. The first login links
+// the identity to the student account with the same verified email, or
+// creates a student account for it, later logins follow the link even if the
+// email changes at the provider. The provider only vouches for students, so
+// staff and recruiter accounts are never linked by email.
+func (h *Handler) oidcUser(ctx context.Context, identity *types.OIDCIdentity) (*types.User, error) {
+	u, err := h.Store.GetUserByIdentity(ctx, identity.Issuer, identity.Subject)
+	if err == nil || !errors.Is(err, types.ErrNotFound) {
+		return u, err
+	}
+
+	email := utils.NormalizeEmail(identity.Email)
+	if !identity.EmailVerified || utils.GetValidator().Var(email, "required,email") != nil {
+		return nil, errEmailUnverified
+	}
+
+	u, err = h.Store.GetUserByEmail(ctx, email)
+	if errors.Is(err, types.ErrNotFound) {
+		if !h.Registration.StudentDomainAllowed(email) {
+			return nil, errDomainNotAllowed
+		}
+		// without a password hash the account can only sign in through the
+		// provider
+		u = &types.User{
+			FirstName: identity.FirstName,
+			LastName:  identity.LastName,
+			Email:     email,
+			UType:     types.UTypeStudent,
+		}
+		if u.FirstName == "" {
+			u.FirstName, _, _ = strings.Cut(email, "@")
+		}
+		u.Id, err = h.Store.CreateUser(ctx, *u)
+		if err == nil {
+			metrics.Registrations.Inc()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.UType != types.UTypeStudent {
+		return nil, errNotLinkable
+	}
+
+	if err := h.Store.LinkIdentity(ctx, u.Id, identity.Issuer, identity.Subject); err != nil {
+		return nil, err
+	}
+	reqctx.Logger(ctx).Info("linked external identity", "user_id", u.Id, "issuer", identity.Issuer)
+	return u, nil
+}
+
+// writeFlowCookie is always lax, the callback is a cross-site redirect from
+// the provider and strict cookies would not be sent with it.
+func (h *Handler) writeFlowCookie(w http.ResponseWriter, value string, ttl time.Duration) {
+	maxAge := int(ttl.Seconds())
+	if ttl == 0 {
+		maxAge = -1
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     flowCookie,
+		Value:    value,
+		HttpOnly: true,
+		Path:     h.Cookie.Path,
+		Domain:   h.Cookie.Domain,
+		Secure:   h.Cookie.Secure,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   maxAge,
+	})
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
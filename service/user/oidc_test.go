@@ -0,0 +1,265 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/auth"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/oidc"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/oidc/oidctest"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type oidcTestServer struct {
+	t           *testing.T
+	router      *chi.Mux
+	store       *MemoryStore
+	provider    *oidctest.Provider
+	authService *auth.AuthService
+}
+
+func newOIDCTestServer(t *testing.T) *oidcTestServer {
+	t.Helper()
+	cfg := config.Default()
+	provider := oidctest.New(t, "placement-app")
+	cfg.OIDC.Enabled = true
+	cfg.OIDC.Issuer = provider.Issuer()
+	cfg.OIDC.ClientId = "placement-app"
+	cfg.Registration.StudentDomains = []string{"college.edu"}
+
+	store := NewMemoryStore()
+	handler := newHandler(t, store, cfg, oidc.NewClient(cfg.OIDC, provider.Client()))
+	router := chi.NewRouter()
+	handler.RegisterRoutes(router)
+	return &oidcTestServer{t: t, router: router, store: store, provider: provider, authService: handler.AuthService.(*auth.AuthService)}
+}
+
+// login runs the whole flow like a browser and returns the callback response.
+func (s *oidcTestServer) login() *httptest.ResponseRecorder {
+	s.t.Helper()
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/oidc/login", nil))
+	if rr.Code != http.StatusFound {
+		s.t.Fatalf("expected a redirect to the provider, got %d: %s", rr.Code, rr.Body)
+	}
+	flow := cookieNamed(rr, flowCookie)
+	if flow == nil || flow.SameSite != http.SameSiteLaxMode || !flow.HttpOnly {
+		s.t.Fatalf("expected a lax, http only flow cookie, got %+v", flow)
+	}
+
+	code, state := s.provider.Authorize(s.t, rr.Header().Get("Location"))
+	return s.callback("/oidc/callback?code="+code+"&state="+state, flow)
+}
+
+func (s *oidcTestServer) callback(target string, flow *http.Cookie) *httptest.ResponseRecorder {
+	s.t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if flow != nil {
+		req.AddCookie(flow)
+	}
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, req)
+	return rr
+}
+
+// signedInAs checks the response redirects to the app with the user's tokens.
+func (s *oidcTestServer) signedInAs(rr *httptest.ResponseRecorder, userId int) {
+	s.t.Helper()
+	if rr.Code != http.StatusFound || rr.Header().Get("Location") != config.Default().OIDC.PostLoginUrl {
+		s.t.Fatalf("expected a redirect to the app, got %d %q: %s", rr.Code, rr.Header().Get("Location"), rr.Body)
+	}
+	for _, name := range []string{"ACCESS_TOKEN", "REFRESH_TOKEN"} {
+		c := cookieNamed(rr, name)
+		if c == nil {
+			s.t.Fatalf("expected a %s cookie", name)
+		}
+		token, err := s.authService.VerifyToken(c.Value)
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		if claims := token.Claims.(*types.CustomClaims); claims.Uid != userId {
+			s.t.Errorf("expected %s for user %d, got %d", name, userId, claims.Uid)
+		}
+	}
+	if c := cookieNamed(rr, flowCookie); c == nil || c.MaxAge >= 0 {
+		s.t.Errorf("expected the flow cookie to be cleared, got %+v", c)
+	}
+}
+
+func cookieNamed(rr *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, c := range rr.Result().Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func expectProblem(t *testing.T, rr *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	if rr.Code != status {
+		t.Fatalf("expected status code %d, got %d: %s", status, rr.Code, rr.Body)
+	}
+	var problem utils.Problem
+	if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Code != code {
+		t.Errorf("expected code %s, got %s", code, problem.Code)
+	}
+	if cookieNamed(rr, "ACCESS_TOKEN") != nil {
+		t.Error("expected no access token")
+	}
+}
+
+func TestOIDCLoginCreatesStudent(t *testing.T) {
+	t.Parallel()
+	s := newOIDCTestServer(t)
+
+	rr := s.login()
+	u, err := s.store.GetUserByEmail(context.Background(), "student@college.edu")
+	if err != nil {
+		t.Fatalf("expected the student to be created, got %v", err)
+	}
+	s.signedInAs(rr, u.Id)
+	if u.UType != types.UTypeStudent || u.FirstName != "Stu" || u.LastName != "Dent" || u.Password != "" {
+		t.Errorf("unexpected user %+v", u)
+	}
+
+	// later logins follow the link, even once the email changed at the provider
+	s.provider.SetClaims(map[string]any{"email": "renamed@college.edu"})
+	s.signedInAs(s.login(), u.Id)
+	if exists, _ := s.store.CheckUserWithEmailExits(context.Background(), "renamed@college.edu"); exists {
+		t.Error("expected no second account")
+	}
+}
+
+func TestOIDCLoginLinksExistingUser(t *testing.T) {
+	t.Parallel()
+	s := newOIDCTestServer(t)
+
+	id, err := s.store.CreateUser(context.Background(), types.User{
+		FirstName: "Stu",
+		LastName:  "Dent",
+		Email:     "student@college.edu",
+		Password:  "hash",
+		UType:     types.UTypeStudent,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.provider.SetClaims(map[string]any{"email": "Student@College.edu"})
+
+	s.signedInAs(s.login(), id)
+	u, err := s.store.GetUserByIdentity(context.Background(), s.provider.Issuer(), "student-1")
+	if err != nil || u.Id != id || u.Password != "hash" {
+		t.Errorf("expected the student to be linked unchanged, got %+v (%v)", u, err)
+	}
+}
+
+func TestOIDCLoginRefusesOtherRoles(t *testing.T) {
+	t.Parallel()
+	s := newOIDCTestServer(t)
+
+	// the provider only vouches for students, other accounts are not linked
+	// by email
+	for _, role := range []string{types.UTypeRecruiter, types.UTypeOfficer, types.UTypeAdmin} {
+		email := role + "@college.edu"
+		if _, err := s.store.CreateUser(context.Background(), types.User{FirstName: "f", Email: email, Password: "hash", UType: role}); err != nil {
+			t.Fatal(err)
+		}
+		s.provider.SetClaims(map[string]any{"sub": role, "email": email})
+
+		expectProblem(t, s.login(), http.StatusForbidden, utils.CodeSSONotLinked)
+		if u, err := s.store.GetUserByIdentity(context.Background(), s.provider.Issuer(), role); err == nil {
+			t.Errorf("expected the %s not to be linked, got %+v", role, u)
+		}
+	}
+}
+
+func TestOIDCLoginRequiresPasswordChange(t *testing.T) {
+	t.Parallel()
+	s := newOIDCTestServer(t)
+
+	s.login()
+	u, err := s.store.GetUserByEmail(context.Background(), "student@college.edu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.store.RequirePasswordChange(context.Background(), u.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	expectProblem(t, s.login(), http.StatusForbidden, utils.CodePasswordChangeNeeded)
+}
+
+func TestOIDCLoginRejected(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		claims   map[string]any
+		wantCode int
+		want     string
+	}{
+		{name: "unverified email", claims: map[string]any{"email_verified": false}, wantCode: http.StatusForbidden, want: utils.CodeEmailUnverified},
+		{name: "no email", claims: map[string]any{"email": nil}, wantCode: http.StatusForbidden, want: utils.CodeEmailUnverified},
+		{name: "new student from another domain", claims: map[string]any{"email": "someone@gmail.com"}, wantCode: http.StatusForbidden, want: utils.CodeEmailDomainNotAllowed},
+		{name: "token for another client", claims: map[string]any{"aud": "another-app"}, wantCode: http.StatusUnauthorized, want: utils.CodeSSOFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := newOIDCTestServer(t)
+			s.provider.SetClaims(tt.claims)
+
+			expectProblem(t, s.login(), tt.wantCode, tt.want)
+			if u, err := s.store.GetUserByIdentity(context.Background(), s.provider.Issuer(), "student-1"); err == nil {
+				t.Errorf("expected no linked user, got %+v", u)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackChecksState(t *testing.T) {
+	t.Parallel()
+	s := newOIDCTestServer(t)
+
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/oidc/login", nil))
+	flow := cookieNamed(rr, flowCookie)
+	code, state := s.provider.Authorize(t, rr.Header().Get("Location"))
+
+	t.Run("missing flow cookie", func(t *testing.T) {
+		expectProblem(t, s.callback("/oidc/callback?code="+code+"&state="+state, nil), http.StatusBadRequest, utils.CodeLoginStateInvalid)
+	})
+	t.Run("forged state", func(t *testing.T) {
+		expectProblem(t, s.callback("/oidc/callback?code="+code+"&state=forged", flow), http.StatusBadRequest, utils.CodeLoginStateInvalid)
+	})
+	t.Run("provider error", func(t *testing.T) {
+		expectProblem(t, s.callback("/oidc/callback?error=access_denied&state="+state, flow), http.StatusUnauthorized, utils.CodeSSOFailed)
+	})
+	t.Run("code is single use", func(t *testing.T) {
+		s.signedInAs(s.callback("/oidc/callback?code="+code+"&state="+state, flow), 1)
+		expectProblem(t, s.callback("/oidc/callback?code="+code+"&state="+state, flow), http.StatusUnauthorized, utils.CodeSSOFailed)
+	})
+}
+
+func TestOIDCRoutesDisabled(t *testing.T) {
+	t.Parallel()
+	handler := newHandler(t, NewMemoryStore(), config.Default(), nil)
+	router := chi.NewRouter()
+	handler.RegisterRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/oidc/login", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected no single sign-on routes, got %d", rr.Code)
+	}
+}
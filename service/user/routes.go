@@ -1,28 +1,44 @@
 package user
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
-	"time"
+	"strconv"
 
 	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/metrics"
 	"github.com/SufyaanKhateeb/college-placement-app-api/middlewares"
+	"github.com/SufyaanKhateeb/college-placement-app-api/reqctx"
 	"github.com/SufyaanKhateeb/college-placement-app-api/service/auth"
 	"github.com/SufyaanKhateeb/college-placement-app-api/types"
 	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 )
 
 type Handler struct {
-	Store       types.UserStore
-	AuthService types.AuthService
+	Store        types.UserStore
+	Tx           types.Transactor
+	AuthService  types.AuthService
+	Signer       types.PasswordResetSigner
+	Auth         config.AuthConfig
+	Cookie       config.CookieConfig
+	Registration config.RegistrationConfig
+	OIDC         config.OIDCConfig
+	// Provider is nil when single sign-on is disabled
+	Provider types.OIDCProvider
 }
 
-func NewHandler(s types.UserStore, authService types.AuthService) *Handler {
+func NewHandler(s types.UserStore, tx types.Transactor, authService types.AuthService, signer types.PasswordResetSigner, authConfig config.AuthConfig, cookie config.CookieConfig, registration config.RegistrationConfig, oidc config.OIDCConfig, provider types.OIDCProvider) *Handler {
 	return &Handler{
-		Store:       s,
-		AuthService: authService,
+		Store:        s,
+		Tx:           tx,
+		AuthService:  authService,
+		Signer:       signer,
+		Auth:         authConfig,
+		Cookie:       cookie,
+		Registration: registration,
+		OIDC:         oidc,
+		Provider:     provider,
 	}
 }
 
@@ -31,23 +47,45 @@ func (h *Handler) RegisterRoutes(r *chi.Mux) {
 	r.Group(func(r chi.Router) {
 		r.Post("/login", h.handleLogin)
 		r.Post("/register", h.handleRegister)
+		r.Post("/password", h.handleChangePassword)
+		r.Post("/password/reset", h.handleCompleteReset)
+		if h.Provider != nil {
+			r.Get("/oidc/login", h.handleOIDCLogin)
+			r.Get("/oidc/callback", h.handleOIDCCallback)
+		}
 	})
 
 	// Private Routes
 	// Require Authentication
 	r.Group(func(r chi.Router) {
-		r.Use(middlewares.AuthMiddleware(h.AuthService), middlewares.RequireUser)
+		r.Use(middlewares.AuthMiddleware(h.AuthService, h.Cookie), middlewares.RequireUser)
 		r.Post("/refresh", h.handleRefresh)
 		r.Post("/logout", h.handleLogout)
 		r.Get("/user", h.getUser)
+		r.Get("/user/sessions", h.listSessions)
+		r.Delete("/user/sessions", h.revokeAllSessions)
+		r.Delete("/user/sessions/{id}", h.revokeSession)
+	})
+
+	// Admin Routes
+	r.Group(func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware(h.AuthService, h.Cookie), middlewares.RequireUser, middlewares.RequireRole(types.UTypeAdmin))
+		r.Get("/admin/users", h.handleListUsers)
+		r.Get("/admin/users/{id}", h.handleGetUser)
+		r.Post("/admin/users/{id}/disable", h.handleDisable)
+		r.Post("/admin/users/{id}/enable", h.handleEnable)
+		r.Put("/admin/users/{id}/role", h.handleUpdateRole)
+		r.Post("/admin/users/{id}/password-reset", h.handleResetPassword)
+		r.Delete("/admin/users/{id}", h.handleDeleteUser)
 	})
 }
 
 func (h *Handler) getUser(w http.ResponseWriter, r *http.Request) {
-	ctxUser := r.Context().Value("user").(types.UserDto)
-	u, err := h.Store.GetUserById(ctxUser.Id)
+	ctxUser := reqctx.MustUser(r.Context())
+
+	u, err := h.Store.GetUserById(r.Context(), ctxUser.Id)
 	if err != nil {
-		utils.WriteJsonError(w, http.StatusBadRequest, fmt.Errorf("invalid user, user not found"))
+		writeStoreError(w, r, err)
 		return
 	}
 
@@ -59,21 +97,27 @@ func (h *Handler) getUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
-	ctxUser := r.Context().Value("user").(types.UserDto)
+	ctxUser := reqctx.MustUser(r.Context())
+
 	utils.WriteJson(w, http.StatusOK, ctxUser)
 }
 
 func (h *Handler) handleLogout(w http.ResponseWriter, r *http.Request) {
-	ctxUser := r.Context().Value("user").(types.UserDto)
-	_, err := h.Store.GetUserById(ctxUser.Id)
+	ctxUser := reqctx.MustUser(r.Context())
+
+	_, err := h.Store.GetUserById(r.Context(), ctxUser.Id)
 	if err != nil {
-		utils.WriteJsonError(w, http.StatusBadRequest, fmt.Errorf("invalid user, user not found"))
+		writeStoreError(w, r, err)
 		return
 	}
 
-	utils.WriteJwtToCookie(w, "ACCESS_TOKEN", "", time.Duration(0))
-	utils.WriteJwtToCookie(w, "REFRESH_TOKEN", "", time.Duration(0))
+	err = h.AuthService.RevokeSession(r.Context(), ctxUser.Id, ctxUser.SessionId)
+	if err != nil && !errors.Is(err, types.ErrNotFound) {
+		writeStoreError(w, r, err)
+		return
+	}
 
+	h.clearTokenCookies(w)
 	utils.WriteJson(w, http.StatusAccepted, nil)
 }
 
@@ -84,34 +128,50 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		utils.WriteJsonError(w, http.StatusBadRequest, err)
 		return
 	}
+	payload.Email = utils.NormalizeEmail(payload.Email)
 
 	if err := utils.GetValidator().Struct(payload); err != nil {
-		errors := err.(validator.ValidationErrors)
-		utils.WriteJsonError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errors))
+		utils.WriteProblem(w, utils.ValidationProblem(err))
 		return
 	}
 
 	// get the user using the email
-	u, err := h.Store.GetUserByEmail(payload.Email)
+	u, err := h.Store.GetUserByEmail(r.Context(), payload.Email)
+	if err != nil && !errors.Is(err, types.ErrNotFound) {
+		writeStoreError(w, r, err)
+		return
+	}
 	if err != nil {
-		utils.WriteJsonError(w, http.StatusBadRequest, fmt.Errorf("not found, invalid email or password"))
+		metrics.Logins.WithLabelValues("failure").Inc()
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInvalidCredentials, "invalid email or password"))
 		return
 	}
+	middlewares.SetAuditTarget(r, "user", strconv.Itoa(u.Id), nil, nil)
 
 	// check if password matches hash
-	if err = auth.CompareHashAndPassword(payload.Password, u.Password); err != nil {
-		utils.WriteJsonError(w, http.StatusBadRequest, fmt.Errorf("not found, invalid email or password"))
+	if err = auth.CompareHashAndPassword(r.Context(), payload.Password, u.Password); err != nil {
+		metrics.Logins.WithLabelValues("failure").Inc()
+		reqctx.Logger(r.Context()).Info("login failed, password mismatch", "user_id", u.Id)
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInvalidCredentials, "invalid email or password"))
+		return
+	}
+	if u.DisabledAt != nil {
+		metrics.Logins.WithLabelValues("failure").Inc()
+		writeDisabled(w)
+		return
+	}
+	if u.PasswordChangeRequired {
+		writePasswordChangeRequired(w)
 		return
 	}
 
-	// create a jwt tokens and insert in cookie
-	accessToken, refreshToken, err := createTokens(h.AuthService, u)
-	if err != nil {
-		utils.WriteJsonError(w, http.StatusInternalServerError, err)
+	if err := h.signIn(w, r, u); err != nil {
+		utils.WriteInternalError(w, r, "error signing in", err)
+		return
 	}
-	utils.WriteJwtToCookie(w, "ACCESS_TOKEN", accessToken, time.Second*time.Duration(config.Env.JWTExpirationTime))
-	utils.WriteJwtToCookie(w, "REFRESH_TOKEN", refreshToken, time.Hour*time.Duration(24*30))
 
+	metrics.Logins.WithLabelValues("success").Inc()
+	reqctx.Logger(r.Context()).Info("user logged in", "user_id", u.Id)
 	utils.WriteJson(w, http.StatusOK, nil)
 }
 
@@ -122,79 +182,248 @@ func (h *Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
 		utils.WriteJsonError(w, http.StatusBadRequest, err)
 		return
 	}
+	payload.Email = utils.NormalizeEmail(payload.Email)
 
 	// validate the payload
 	if err := utils.GetValidator().Struct(payload); err != nil {
-		errors := err.(validator.ValidationErrors)
-		utils.WriteJsonError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errors))
+		utils.WriteProblem(w, utils.ValidationProblem(err))
+		return
+	}
+
+	// self-registration is for students, who must use a college address
+	if !h.Registration.StudentDomainAllowed(payload.Email) {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusForbidden, utils.CodeEmailDomainNotAllowed, "students must register with their college email address"))
 		return
 	}
 
 	// check if the user exists
-	exists, err := h.Store.CheckUserWithEmailExits(payload.Email)
+	exists, err := h.Store.CheckUserWithEmailExits(r.Context(), payload.Email)
 	if err != nil {
-		utils.WriteJsonError(w, http.StatusInternalServerError, err)
+		writeStoreError(w, r, err)
 		return
 	}
 	if exists {
-		utils.WriteJsonError(w, http.StatusBadRequest, fmt.Errorf("user with email %s already exists", payload.Email))
+		writeStoreError(w, r, types.ErrDuplicateEmail)
 		return
 	}
 
 	// create a new user
-	hashedPassword, err := auth.HashPassword(payload.Password)
+	hashedPassword, err := auth.HashPassword(r.Context(), payload.Password)
 
 	if err != nil {
-		utils.WriteJsonError(w, http.StatusInternalServerError, err)
+		utils.WriteInternalError(w, r, "hashing password failed", err)
 		return
 	}
 
-	id, err := h.Store.CreateUser(types.User{
+	id, err := h.Store.CreateUser(r.Context(), types.User{
 		FirstName: payload.FirstName,
 		LastName:  payload.LastName,
 		Email:     payload.Email,
 		Password:  hashedPassword,
+		UType:     types.UTypeStudent,
 	})
 	if err != nil {
-		utils.WriteJsonError(w, http.StatusInternalServerError, err)
+		writeStoreError(w, r, err)
 		return
 	}
 
-	// create a jwt access token and insert in cookie
-	accessToken, refreshToken, err := createTokens(h.AuthService, &types.User{
+	newUser := &types.User{
 		Id:        id,
 		FirstName: payload.FirstName,
 		LastName:  payload.LastName,
 		Email:     payload.Email,
+		UType:     types.UTypeStudent,
+	}
+	middlewares.SetAuditTarget(r, "user", strconv.Itoa(id), nil, newUser)
+
+	if err := h.signIn(w, r, newUser); err != nil {
+		utils.WriteInternalError(w, r, "error signing in", err)
+		return
+	}
+
+	metrics.Registrations.Inc()
+	reqctx.Logger(r.Context()).Info("user registered", "user_id", id)
+	utils.WriteJson(w, http.StatusCreated, nil)
+}
+
+// handleChangePassword takes the current password rather than a session.
+// Users whose password an admin reset must use their reset link instead, as
+// whoever made the reset necessary may know the current one.
+func (h *Handler) handleChangePassword(w http.ResponseWriter, r *http.Request) {
+	var payload types.ChangePasswordPayload
+	if err := utils.ParseJson(r, &payload); err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err)
+		return
+	}
+	payload.Email = utils.NormalizeEmail(payload.Email)
+
+	if err := utils.GetValidator().Struct(payload); err != nil {
+		utils.WriteProblem(w, utils.ValidationProblem(err))
+		return
+	}
+
+	u, err := h.Store.GetUserByEmail(r.Context(), payload.Email)
+	if err != nil && !errors.Is(err, types.ErrNotFound) {
+		writeStoreError(w, r, err)
+		return
+	}
+	if err != nil || auth.CompareHashAndPassword(r.Context(), payload.Password, u.Password) != nil {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInvalidCredentials, "invalid email or password"))
+		return
+	}
+	middlewares.SetAuditTarget(r, "user", strconv.Itoa(u.Id), nil, nil)
+	if u.DisabledAt != nil {
+		writeDisabled(w)
+		return
+	}
+	if u.PasswordChangeRequired {
+		writePasswordChangeRequired(w)
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(r.Context(), payload.NewPassword)
+	if err != nil {
+		utils.WriteInternalError(w, r, "hashing password failed", err)
+		return
+	}
+	err = h.Tx.InTx(r.Context(), func(s types.Stores) error {
+		if err := s.User.UpdatePassword(r.Context(), u.Id, hashedPassword); err != nil {
+			return err
+		}
+		// whoever knew the old password is signed out
+		return s.Auth.RevokeSessions(r.Context(), u.Id)
 	})
 	if err != nil {
-		utils.WriteJsonError(w, http.StatusInternalServerError, err)
+		writeStoreError(w, r, err)
 		return
 	}
-	utils.WriteJwtToCookie(w, "ACCESS_TOKEN", accessToken, time.Second*time.Duration(config.Env.JWTExpirationTime))
-	utils.WriteJwtToCookie(w, "REFRESH_TOKEN", refreshToken, time.Hour*time.Duration(24*30))
 
-	utils.WriteJson(w, http.StatusCreated, nil)
+	if err := h.signIn(w, r, u); err != nil {
+		utils.WriteInternalError(w, r, "error signing in", err)
+		return
+	}
+
+	reqctx.Logger(r.Context()).Info("password changed", "user_id", u.Id)
+	utils.WriteJson(w, http.StatusOK, nil)
 }
 
-func createTokens(authService types.AuthService, u *types.User) (string, string, error) {
-	expirationTime := time.Second * time.Duration(config.Env.JWTExpirationTime)
-	accessToken, err := authService.SignJwt(expirationTime, types.CustomClaims{
-		Uid:   u.Id,
-		UType: "",
+// handleCompleteReset sets the password through the link an admin handed out
+// when resetting it. The link names the reset, so it works once and not after
+// a newer reset.
+func (h *Handler) handleCompleteReset(w http.ResponseWriter, r *http.Request) {
+	var payload types.ResetPasswordPayload
+	if err := utils.ParseJson(r, &payload); err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.GetValidator().Struct(payload); err != nil {
+		utils.WriteProblem(w, utils.ValidationProblem(err))
+		return
+	}
+
+	id, resetAt, err := h.Signer.VerifyPasswordReset(payload.Token)
+	if err != nil {
+		writeResetInvalid(w)
+		return
+	}
+	middlewares.SetAuditTarget(r, "user", strconv.Itoa(id), nil, nil)
+
+	hashedPassword, err := auth.HashPassword(r.Context(), payload.NewPassword)
+	if err != nil {
+		utils.WriteInternalError(w, r, "hashing password failed", err)
+		return
+	}
+
+	var u *types.User
+	err = h.Tx.InTx(r.Context(), func(s types.Stores) error {
+		var err error
+		if u, err = s.User.GetUserById(r.Context(), id); err != nil {
+			return err
+		}
+		if u.PasswordResetAt == nil || !u.PasswordResetAt.Equal(resetAt) {
+			return errResetUsed
+		}
+		if u.DisabledAt != nil {
+			return nil
+		}
+		if err := s.User.UpdatePassword(r.Context(), id, hashedPassword); err != nil {
+			return err
+		}
+		return s.Auth.RevokeSessions(r.Context(), id)
 	})
+	if errors.Is(err, types.ErrNotFound) || errors.Is(err, errResetUsed) {
+		writeResetInvalid(w)
+		return
+	}
 	if err != nil {
-		return "", "", nil
+		writeStoreError(w, r, err)
+		return
 	}
+	if u.DisabledAt != nil {
+		writeDisabled(w)
+		return
+	}
+
+	if err := h.signIn(w, r, u); err != nil {
+		utils.WriteInternalError(w, r, "error signing in", err)
+		return
+	}
+
+	reqctx.Logger(r.Context()).Info("password reset completed", "user_id", id)
+	utils.WriteJson(w, http.StatusOK, nil)
+}
+
+// errResetUsed rolls back a reset whose link was used or replaced.
+var errResetUsed = errors.New("password reset used or replaced")
+
+func writeResetInvalid(w http.ResponseWriter) {
+	utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodePasswordResetInvalid, "the password reset link is invalid, was used or has expired"))
+}
+
+func writePasswordChangeRequired(w http.ResponseWriter) {
+	utils.WriteProblem(w, utils.NewProblem(http.StatusForbidden, utils.CodePasswordChangeNeeded, "you must choose a new password through the reset link from your admin"))
+}
 
-	expirationTime = time.Hour * time.Duration(24*30)
-	refreshToken, err := authService.SignJwt(expirationTime, types.CustomClaims{
+func writeDisabled(w http.ResponseWriter) {
+	utils.WriteProblem(w, utils.NewProblem(http.StatusForbidden, utils.CodeAccountDisabled, "your account has been disabled"))
+}
+
+// writeStoreError maps the store's sentinel errors to their status, anything
+// else is a failing database and is logged and reported as a 500.
+func writeStoreError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, types.ErrNotFound):
+		utils.WriteProblem(w, utils.NewProblem(http.StatusNotFound, utils.CodeUserNotFound, "user not found"))
+	case errors.Is(err, types.ErrDuplicateEmail):
+		utils.WriteProblem(w, utils.NewProblem(http.StatusConflict, utils.CodeEmailTaken, "a user with this email already exists"))
+	default:
+		utils.WriteInternalError(w, r, "user store failed", err)
+	}
+}
+
+// signIn starts a session for u and sets the cookies with its tokens.
+func (h *Handler) signIn(w http.ResponseWriter, r *http.Request, u *types.User) error {
+	sid, err := h.AuthService.StartSession(r.Context(), u.Id, r.UserAgent(), middlewares.ClientIp(r))
+	if err != nil {
+		return err
+	}
+
+	claims := types.CustomClaims{
 		Uid:   u.Id,
-		UType: "",
-	})
+		UType: u.UType,
+		Sid:   sid,
+	}
+	accessToken, err := h.AuthService.SignJwt(h.AuthService.AccessTokenTTL(), claims)
+	if err != nil {
+		return err
+	}
+	refreshToken, err := h.AuthService.SignJwt(h.AuthService.RefreshTokenTTL(), claims)
 	if err != nil {
-		return "", "", nil
+		return err
 	}
 
-	return accessToken, refreshToken, nil
+	utils.WriteJwtToCookie(w, "ACCESS_TOKEN", accessToken, h.AuthService.AccessTokenTTL(), h.Cookie)
+	utils.WriteJwtToCookie(w, "REFRESH_TOKEN", refreshToken, h.AuthService.RefreshTokenTTL(), h.Cookie)
+	return nil
 }
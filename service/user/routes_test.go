@@ -2,21 +2,28 @@ package user
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
-	"time"
 
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/reqctx"
 	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
 	"github.com/go-chi/chi/v5"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 func TestUserServiceHandlers(t *testing.T) {
-	userStore := &mockUserStore{UserExists: true}
-	handler := NewHandler(userStore, &mockAuthService{})
+	t.Parallel()
+	userStore := NewMemoryStore()
+	cfg := config.Default()
+	cfg.Registration.StudentDomains = []string{"email.com"}
+	handler := newHandler(t, userStore, cfg, nil)
 
 	t.Run("should fail if the user payload is invalid", func(t *testing.T) {
 		payload := types.RegisterUserPayload{
@@ -30,6 +37,7 @@ func TestUserServiceHandlers(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
+		req.Header.Set("Content-Type", "application/json")
 
 		rr := httptest.NewRecorder()
 		router := chi.NewRouter()
@@ -40,9 +48,26 @@ func TestUserServiceHandlers(t *testing.T) {
 		if rr.Code != http.StatusBadRequest {
 			t.Errorf("expected status code %d, got %d", http.StatusBadRequest, rr.Code)
 		}
+		if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("expected problem content type, got %q", ct)
+		}
+
+		var problem utils.Problem
+		if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+			t.Fatal(err)
+		}
+		if problem.Code != utils.CodeValidationFailed {
+			t.Errorf("expected code %s, got %s", utils.CodeValidationFailed, problem.Code)
+		}
+		fields := map[string]bool{}
+		for _, fe := range problem.Errors {
+			fields[fe.Field] = true
+		}
+		if !fields["email"] || !fields["password"] || len(fields) != 2 {
+			t.Errorf("expected errors for email and password, got %+v", problem.Errors)
+		}
 	})
 
-	userStore.UserExists = false
 	t.Run("should create user for valid payload", func(t *testing.T) {
 		payload := types.RegisterUserPayload{
 			FirstName: "fname",
@@ -55,6 +80,7 @@ func TestUserServiceHandlers(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
+		req.Header.Set("Content-Type", "application/json")
 
 		rr := httptest.NewRecorder()
 		router := chi.NewRouter()
@@ -66,43 +92,175 @@ func TestUserServiceHandlers(t *testing.T) {
 			t.Errorf("expected status code %d, got %d", http.StatusCreated, rr.Code)
 		}
 	})
+
+	t.Run("should send anonymous requests to login before reaching the handler", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/user", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := chi.NewRouter()
+
+		handler.RegisterRoutes(router)
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusFound {
+			t.Errorf("expected status code %d, got %d", http.StatusFound, rr.Code)
+		}
+	})
 }
 
-type mockAuthService struct{}
+func TestRegisterNormalizesEmail(t *testing.T) {
+	t.Parallel()
+	store := NewMemoryStore()
+	cfg := config.Default()
+	cfg.Registration.StudentDomains = []string{"college.edu"}
+	handler := newHandler(t, store, cfg, nil)
+	router := chi.NewRouter()
+	router.Post("/register", handler.handleRegister)
+	router.Post("/login", handler.handleLogin)
 
-func (a *mockAuthService) SignJwt(expirationTime time.Duration, claims types.CustomClaims) (string, error) {
-	return "", nil
+	post := func(path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := post("/register", `{"firstName":"Alice","lastName":"A","email":" Alice@College.EDU ","password":"pass@123"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body)
+	}
+	if _, err := store.GetUserByEmail(context.Background(), "alice@college.edu"); err != nil {
+		t.Fatalf("expected the normalized email to be stored, got %v", err)
+	}
+
+	rr = post("/register", `{"firstName":"Alice","lastName":"A","email":"alice@college.edu","password":"pass@123"}`)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected a differently cased email to conflict, got %d", rr.Code)
+	}
+
+	rr = post("/login", `{"email":"ALICE@college.edu","password":"pass@123"}`)
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected login with a differently cased email to succeed, got %d: %s", rr.Code, rr.Body)
+	}
+
+	for _, email := range []string{"bob@gmail.com", "bob@college.edu.example.com"} {
+		rr = post("/register", `{"firstName":"Bob","lastName":"B","email":"`+email+`","password":"pass@123"}`)
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("expected %s to be refused, got %d", email, rr.Code)
+			continue
+		}
+		var problem utils.Problem
+		if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+			t.Fatal(err)
+		}
+		if problem.Code != utils.CodeEmailDomainNotAllowed {
+			t.Errorf("expected code %s, got %s", utils.CodeEmailDomainNotAllowed, problem.Code)
+		}
+	}
 }
 
-func (a *mockAuthService) VerifyToken(tkn string) (*jwt.Token, error) {
-	return &jwt.Token{}, nil
+// failingStore wraps a memory store and returns the configured errors
+// instead of calling it.
+type failingStore struct {
+	*MemoryStore
+	existsErr error
+	getErr    error
+	createErr error
 }
 
-type mockUserStore struct {
-	UserExists bool
+func (s *failingStore) CheckUserWithEmailExits(ctx context.Context, email string) (bool, error) {
+	if s.existsErr != nil {
+		return false, s.existsErr
+	}
+	return s.MemoryStore.CheckUserWithEmailExits(ctx, email)
 }
 
-func (s *mockUserStore) CheckUserWithEmailExits(email string) (bool, error) {
-	if s.UserExists {
-		return true, nil
+func (s *failingStore) GetUserById(ctx context.Context, id int) (*types.User, error) {
+	if s.getErr != nil {
+		return nil, s.getErr
 	}
-	return false, nil
+	return s.MemoryStore.GetUserById(ctx, id)
 }
 
-func (s *mockUserStore) GetUserByEmail(email string) (*types.User, error) {
-	if s.UserExists {
-		return &types.User{}, nil
+func (s *failingStore) GetUserByEmail(ctx context.Context, email string) (*types.User, error) {
+	if s.getErr != nil {
+		return nil, s.getErr
 	}
-	return nil, fmt.Errorf("user not found")
+	return s.MemoryStore.GetUserByEmail(ctx, email)
 }
 
-func (s *mockUserStore) GetUserById(id int) (*types.User, error) {
-	if s.UserExists {
-		return &types.User{}, nil
+func (s *failingStore) CreateUser(ctx context.Context, u types.User) (int, error) {
+	if s.createErr != nil {
+		return 0, s.createErr
 	}
-	return nil, fmt.Errorf("user not found")
+	return s.MemoryStore.CreateUser(ctx, u)
 }
 
-func (s *mockUserStore) CreateUser(u types.User) (int, error) {
-	return 0, nil
+func TestUserHandlersStoreErrors(t *testing.T) {
+	t.Parallel()
+	dbErr := errors.New("connection refused")
+	register := `{"firstName":"fname","lastName":"lname","email":"taken@email.com","password":"pass@123"}`
+	login := `{"email":"taken@email.com","password":"pass@123"}`
+
+	tests := []struct {
+		name     string
+		store    *failingStore
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantErr  string
+	}{
+		{name: "register with a taken email", store: &failingStore{}, method: http.MethodPost, path: "/register", body: register, wantCode: http.StatusConflict, wantErr: utils.CodeEmailTaken},
+		{name: "register losing an insert race", store: &failingStore{createErr: types.ErrDuplicateEmail}, method: http.MethodPost, path: "/register", body: strings.Replace(register, "taken@", "new@", 1), wantCode: http.StatusConflict, wantErr: utils.CodeEmailTaken},
+		{name: "register with the database down", store: &failingStore{existsErr: dbErr}, method: http.MethodPost, path: "/register", body: register, wantCode: http.StatusInternalServerError, wantErr: utils.CodeInternal},
+		{name: "login with an unknown email", store: &failingStore{getErr: types.ErrNotFound}, method: http.MethodPost, path: "/login", body: login, wantCode: http.StatusBadRequest, wantErr: utils.CodeInvalidCredentials},
+		{name: "login with the database down", store: &failingStore{getErr: dbErr}, method: http.MethodPost, path: "/login", body: login, wantCode: http.StatusInternalServerError, wantErr: utils.CodeInternal},
+		{name: "get a deleted user", store: &failingStore{getErr: fmt.Errorf("getting user 1: %w", types.ErrNotFound)}, method: http.MethodGet, path: "/user", wantCode: http.StatusNotFound, wantErr: utils.CodeUserNotFound},
+		{name: "get user with the database down", store: &failingStore{getErr: dbErr}, method: http.MethodGet, path: "/user", wantCode: http.StatusInternalServerError, wantErr: utils.CodeInternal},
+		{name: "logout a deleted user", store: &failingStore{getErr: types.ErrNotFound}, method: http.MethodPost, path: "/logout", wantCode: http.StatusNotFound, wantErr: utils.CodeUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.store.MemoryStore = NewMemoryStore()
+			if _, err := tt.store.MemoryStore.CreateUser(context.Background(), types.User{Email: "taken@email.com"}); err != nil {
+				t.Fatal(err)
+			}
+			cfg := config.Default()
+			cfg.Registration.StudentDomains = []string{"email.com"}
+			handler := newHandler(t, tt.store, cfg, nil)
+
+			router := chi.NewRouter()
+			router.Post("/register", handler.handleRegister)
+			router.Post("/login", handler.handleLogin)
+			router.Get("/user", handler.getUser)
+			router.Post("/logout", handler.handleLogout)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(reqctx.WithUser(req.Context(), types.UserDto{Id: 1}))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("expected status code %d, got %d: %s", tt.wantCode, rr.Code, rr.Body)
+			}
+			var problem utils.Problem
+			if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Code != tt.wantErr {
+				t.Errorf("expected code %s, got %s", tt.wantErr, problem.Code)
+			}
+			if strings.Contains(problem.Detail, dbErr.Error()) {
+				t.Errorf("database error leaked to the client: %s", problem.Detail)
+			}
+		})
+	}
 }
@@ -0,0 +1,79 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/middlewares"
+	"github.com/SufyaanKhateeb/college-placement-app-api/reqctx"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) listSessions(w http.ResponseWriter, r *http.Request) {
+	ctxUser := reqctx.MustUser(r.Context())
+
+	sessions, err := h.AuthService.ListSessions(r.Context(), ctxUser.Id)
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+
+	dtos := make([]types.SessionDto, 0, len(sessions))
+	for _, s := range sessions {
+		dtos = append(dtos, types.SessionDto{Session: s, Current: s.Id == ctxUser.SessionId})
+	}
+	utils.WriteJson(w, http.StatusOK, dtos)
+}
+
+// revokeSession signs out a single session, e.g. on a lost device. Its tokens
+// are refused from the next request on.
+func (h *Handler) revokeSession(w http.ResponseWriter, r *http.Request) {
+	ctxUser := reqctx.MustUser(r.Context())
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusBadRequest, utils.CodeInvalidId, "invalid session id"))
+		return
+	}
+
+	err = h.AuthService.RevokeSession(r.Context(), ctxUser.Id, id)
+	if errors.Is(err, types.ErrNotFound) {
+		utils.WriteProblem(w, utils.NewProblem(http.StatusNotFound, utils.CodeSessionNotFound, "session not found"))
+		return
+	}
+	if err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	middlewares.SetAuditTarget(r, "session", strconv.Itoa(id), nil, nil)
+
+	if id == ctxUser.SessionId {
+		h.clearTokenCookies(w)
+	}
+	reqctx.Logger(r.Context()).Info("session revoked", "session_id", id)
+	utils.WriteJson(w, http.StatusAccepted, nil)
+}
+
+// revokeAllSessions signs the user out everywhere, including this browser.
+func (h *Handler) revokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	ctxUser := reqctx.MustUser(r.Context())
+
+	if err := h.AuthService.RevokeSessions(r.Context(), ctxUser.Id); err != nil {
+		writeStoreError(w, r, err)
+		return
+	}
+	middlewares.SetAuditTarget(r, "user", strconv.Itoa(ctxUser.Id), nil, nil)
+
+	h.clearTokenCookies(w)
+	reqctx.Logger(r.Context()).Info("all sessions revoked")
+	utils.WriteJson(w, http.StatusAccepted, nil)
+}
+
+func (h *Handler) clearTokenCookies(w http.ResponseWriter) {
+	utils.WriteJwtToCookie(w, "ACCESS_TOKEN", "", time.Duration(0), h.Cookie)
+	utils.WriteJwtToCookie(w, "REFRESH_TOKEN", "", time.Duration(0), h.Cookie)
+}
@@ -0,0 +1,117 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/SufyaanKhateeb/college-placement-app-api/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestSessions(t *testing.T) {
+	t.Parallel()
+	cfg := config.Default()
+	cfg.Registration.StudentDomains = []string{"college.edu"}
+	handler := newHandler(t, NewMemoryStore(), cfg, nil)
+	router := chi.NewRouter()
+	handler.RegisterRoutes(router)
+
+	// do sends the request from a browser holding cookies
+	do := func(method, path, userAgent, body string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	listSessions := func(cookies []*http.Cookie) []types.SessionDto {
+		t.Helper()
+		rr := do(http.MethodGet, "/user/sessions", "", "", cookies)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+		}
+		var sessions []types.SessionDto
+		if err := json.NewDecoder(rr.Body).Decode(&sessions); err != nil {
+			t.Fatal(err)
+		}
+		return sessions
+	}
+
+	rr := do(http.MethodPost, "/register", "Laptop", `{"firstName":"f","lastName":"l","email":"s@college.edu","password":"pass@123"}`, nil)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body)
+	}
+	laptop := rr.Result().Cookies()
+	phone := do(http.MethodPost, "/login", "Phone", `{"email":"s@college.edu","password":"pass@123"}`, nil).Result().Cookies()
+	tablet := do(http.MethodPost, "/login", "Tablet", `{"email":"s@college.edu","password":"pass@123"}`, nil).Result().Cookies()
+
+	sessions := listSessions(laptop)
+	if len(sessions) != 3 {
+		t.Fatalf("expected 3 sessions, got %+v", sessions)
+	}
+	agents := map[string]types.SessionDto{}
+	for _, s := range sessions {
+		agents[s.UserAgent] = s
+	}
+	if !agents["Laptop"].Current || agents["Phone"].Current || agents["Tablet"].Current || agents["Phone"].Ip == "" {
+		t.Errorf("expected only the laptop session to be current, got %+v", sessions)
+	}
+
+	// kill the stolen phone from the laptop
+	phoneId := strconv.Itoa(agents["Phone"].Id)
+	if rr := do(http.MethodDelete, "/user/sessions/"+phoneId, "", "", laptop); rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusAccepted, rr.Code, rr.Body)
+	}
+	if rr := do(http.MethodGet, "/user", "", "", phone); rr.Code != http.StatusFound {
+		t.Errorf("expected the phone to be signed out, got %d", rr.Code)
+	}
+	// a revoked session is gone
+	expectProblem(t, do(http.MethodDelete, "/user/sessions/"+phoneId, "", "", laptop), http.StatusNotFound, utils.CodeSessionNotFound)
+	if len(listSessions(laptop)) != 2 {
+		t.Error("expected the phone session to be unlisted")
+	}
+
+	// logging out ends the session, not only the cookies
+	if rr := do(http.MethodPost, "/logout", "", "", tablet); rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusAccepted, rr.Code, rr.Body)
+	}
+	if rr := do(http.MethodGet, "/user", "", "", tablet); rr.Code != http.StatusFound {
+		t.Errorf("expected the tablet to be signed out, got %d", rr.Code)
+	}
+
+	other := do(http.MethodPost, "/register", "Other", `{"firstName":"o","lastName":"o","email":"o@college.edu","password":"pass@123"}`, nil).Result().Cookies()
+	otherSessions := listSessions(other)
+	if len(otherSessions) != 1 {
+		t.Fatalf("expected only the other user's session, got %+v", otherSessions)
+	}
+	// another user's session is not found
+	expectProblem(t, do(http.MethodDelete, "/user/sessions/"+strconv.Itoa(otherSessions[0].Id), "", "", laptop), http.StatusNotFound, utils.CodeSessionNotFound)
+	expectProblem(t, do(http.MethodDelete, "/user/sessions/abc", "", "", laptop), http.StatusBadRequest, utils.CodeInvalidId)
+
+	rr = do(http.MethodDelete, "/user/sessions", "", "", laptop)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusAccepted, rr.Code, rr.Body)
+	}
+	for _, c := range rr.Result().Cookies() {
+		if c.Value != "" {
+			t.Errorf("expected cookies to be cleared, got %s=%s", c.Name, c.Value)
+		}
+	}
+	if rr := do(http.MethodGet, "/user", "", "", laptop); rr.Code != http.StatusFound {
+		t.Errorf("expected the laptop to be signed out, got %d", rr.Code)
+	}
+	if len(listSessions(other)) != 1 {
+		t.Error("expected the other user to stay signed in")
+	}
+}
@@ -2,113 +2,245 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/SufyaanKhateeb/college-placement-app-api/db"
 	"github.com/SufyaanKhateeb/college-placement-app-api/types"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+const userColumns = "id, firstName, lastName, email, password, createdAt, uType, company, branch, disabledAt, deletedAt, passwordChangeRequired, passwordResetAt"
+
+// notDeleted limits lookups and updates to users that were not soft deleted
+const notDeleted = "deletedAt is null"
+
+var statusConds = map[string]string{
+	types.UserStatusActive:   notDeleted + " and disabledAt is null",
+	types.UserStatusDisabled: notDeleted + " and disabledAt is not null",
+	types.UserStatusDeleted:  "deletedAt is not null",
+}
+
+var sortColumns = map[string]string{
+	types.UserSortCreatedAt: "createdAt",
+	types.UserSortName:      "lower(firstName), lower(lastName)",
+	types.UserSortEmail:     "lower(email)",
+}
+
+// uniqueViolation is the Postgres error code for a broken unique constraint,
+// on users the only one is the case-insensitive index on email
+const uniqueViolation = "23505"
+
+// foreignKeyViolation is returned when linking an identity to a missing user
+const foreignKeyViolation = "23503"
+
 type Store struct {
-	db *pgxpool.Pool
+	db db.Querier
 }
 
-func NewStore(db *pgxpool.Pool) *Store {
+func NewStore(db db.Querier) *Store {
 	return &Store{
 		db: db,
 	}
 }
 
-func (s *Store) CheckUserWithEmailExits(email string) (bool, error) {
-	rows, err := s.db.Query(context.Background(), "select exists(select id from users where email = $1)", email)
+func (s *Store) CheckUserWithEmailExits(ctx context.Context, email string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow(ctx, "select exists(select 1 from users where lower(email) = lower($1))", email).Scan(&exists)
 	if err != nil {
-		return true, err
-	}
-
-	exists := true
-	for rows.Next() {
-		err = rows.Scan(&exists)
-		if err != nil {
-			return true, err
-		}
+		return false, err
 	}
 	return exists, nil
 }
 
-func (s *Store) GetUserByEmail(email string) (*types.User, error) {
-	rows, err := s.db.Query(context.Background(), "select * from users where email = $1", email)
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (*types.User, error) {
+	u, err := scanUser(s.db.QueryRow(ctx, "select "+userColumns+" from users where lower(email) = lower($1) and "+notDeleted, email))
 	if err != nil {
-		return nil, err
-	}
-
-	u := new(types.User)
-	for rows.Next() {
-		u, err = scanRowIntoUser(rows)
-		if err != nil {
-			return nil, err
-		}
+		return nil, fmt.Errorf("getting user by email: %w", err)
 	}
+	return u, nil
+}
 
-	if u.Id == 0 {
-		return nil, fmt.Errorf("user not found")
+func (s *Store) GetUserById(ctx context.Context, id int) (*types.User, error) {
+	u, err := scanUser(s.db.QueryRow(ctx, "select "+userColumns+" from users where id = $1 and "+notDeleted, id))
+	if err != nil {
+		return nil, fmt.Errorf("getting user %d: %w", id, err)
 	}
-
 	return u, nil
 }
 
-func scanRowIntoUser(rows pgx.Rows) (*types.User, error) {
+func scanUser(row pgx.Row) (*types.User, error) {
 	u := new(types.User)
-
-	err := rows.Scan(
+	err := row.Scan(
 		&u.Id,
 		&u.FirstName,
 		&u.LastName,
 		&u.Email,
 		&u.Password,
 		&u.CreatedAt,
+		&u.UType,
+		&u.Company,
+		&u.Branch,
+		&u.DisabledAt,
+		&u.DeletedAt,
+		&u.PasswordChangeRequired,
+		&u.PasswordResetAt,
 	)
-
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
+	return u, nil
+}
+
+func (s *Store) CreateUser(ctx context.Context, u types.User) (int, error) {
+	var id int
+	err := s.db.QueryRow(ctx,
+		"insert into users (firstName, lastName, email, password, uType, company, branch) values ($1, $2, $3, $4, $5, $6, $7) returning id",
+		u.FirstName, u.LastName, u.Email, u.Password, u.UType, u.Company, u.Branch,
+	).Scan(&id)
 
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return 0, types.ErrDuplicateEmail
+	}
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *Store) GetUserByIdentity(ctx context.Context, issuer, subject string) (*types.User, error) {
+	u, err := scanUser(s.db.QueryRow(ctx,
+		"select "+userColumns+" from users where id = (select userId from user_identities where issuer = $1 and subject = $2) and "+notDeleted,
+		issuer, subject,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("getting user by identity: %w", err)
+	}
 	return u, nil
 }
 
-func (s *Store) GetUserById(id int) (*types.User, error) {
-	rows, err := s.db.Query(context.Background(), "select * from users where id = $1", id)
+// LinkIdentity is idempotent, linking an identity that is already linked
+// leaves the existing link in place.
+func (s *Store) LinkIdentity(ctx context.Context, userId int, issuer, subject string) error {
+	_, err := s.db.Exec(ctx,
+		"insert into user_identities (userId, issuer, subject) values ($1, $2, $3) on conflict (issuer, subject) do nothing",
+		userId, issuer, subject,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return types.ErrNotFound
+	}
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("linking identity to user %d: %w", userId, err)
 	}
+	return nil
+}
 
-	u := new(types.User)
+func (s *Store) SearchUsers(ctx context.Context, filter types.UserFilter) ([]types.User, error) {
+	var conds []string
+	var args []any
+	if filter.Status != "" {
+		cond, ok := statusConds[filter.Status]
+		if !ok {
+			return nil, fmt.Errorf("unknown user status %s", filter.Status)
+		}
+		conds = append(conds, cond)
+	} else {
+		conds = append(conds, notDeleted)
+	}
+	if filter.Query != "" {
+		args = append(args, "%"+likeEscaper.Replace(filter.Query)+"%")
+		conds = append(conds, fmt.Sprintf("((firstName || ' ' || lastName) ilike $%d or email ilike $%d)", len(args), len(args)))
+	}
+	if filter.UType != "" {
+		args = append(args, filter.UType)
+		conds = append(conds, fmt.Sprintf("uType = $%d", len(args)))
+	}
+	if filter.Branch != "" {
+		args = append(args, filter.Branch)
+		conds = append(conds, fmt.Sprintf("lower(branch) = lower($%d)", len(args)))
+	}
+
+	field, desc := strings.CutPrefix(filter.Sort, "-")
+	if field == "" {
+		field, desc = types.UserSortCreatedAt, true
+	}
+	order, ok := sortColumns[field]
+	if !ok {
+		return nil, fmt.Errorf("unknown user sort %s", filter.Sort)
+	}
+	dir := " asc"
+	if desc {
+		dir = " desc"
+	}
+	// every column gets the direction, id keeps pages stable between requests
+	order = strings.ReplaceAll(order, ",", dir+",") + dir + ", id" + dir
+
+	query := "select " + userColumns + " from users where " + strings.Join(conds, " and ") + " order by " + order
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" limit $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" offset $%d", len(args))
+	}
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []types.User{}
 	for rows.Next() {
-		u, err = scanRowIntoUser(rows)
+		u, err := scanUser(rows)
 		if err != nil {
 			return nil, err
 		}
+		users = append(users, *u)
 	}
+	return users, rows.Err()
+}
 
-	if u.Id == 0 {
-		return nil, fmt.Errorf("user not found")
-	}
+// likeEscaper keeps % and _ typed by admins from acting as wildcards
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
 
-	return u, nil
+func (s *Store) SetUserDisabled(ctx context.Context, id int, disabled bool) error {
+	// keep the original time when disabling twice
+	return s.update(ctx, id, "disabledAt = case when $2 then coalesce(disabledAt, now()) end", disabled)
 }
 
-func (s *Store) CreateUser(u types.User) (int, error) {
-	var lastInserId int
-	rows, err := s.db.Query(context.Background(), "insert into users (firstName, lastName, email, password) values ($1,$2,$3,$4) returning id", u.FirstName, u.LastName, u.Email, u.Password)
-	if err != nil {
-		return 0, err
-	}
+func (s *Store) SetUserRole(ctx context.Context, id int, uType, company string) error {
+	return s.update(ctx, id, "uType = $2, company = $3", uType, company)
+}
+
+func (s *Store) RequirePasswordChange(ctx context.Context, id int) error {
+	return s.update(ctx, id, "passwordChangeRequired = true, passwordResetAt = now()")
+}
+
+func (s *Store) UpdatePassword(ctx context.Context, id int, hash string) error {
+	return s.update(ctx, id, "password = $2, passwordChangeRequired = false, passwordResetAt = null", hash)
+}
 
-	// get the id of the created user
-	rows.Next()
-	err = rows.Scan(&lastInserId)
+func (s *Store) DeleteUser(ctx context.Context, id int) error {
+	return s.update(ctx, id, "deletedAt = now()")
+}
+
+// update sets columns on a user that is not deleted, args start at $2.
+func (s *Store) update(ctx context.Context, id int, set string, args ...any) error {
+	tag, err := s.db.Exec(ctx, "update users set "+set+" where id = $1 and "+notDeleted, append([]any{id}, args...)...)
 	if err != nil {
-		return lastInserId, err
+		return fmt.Errorf("updating user %d: %w", id, err)
 	}
-
-	return lastInserId, nil
+	if tag.RowsAffected() == 0 {
+		return types.ErrNotFound
+	}
+	return nil
 }
@@ -0,0 +1,25 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/storetest"
+	"github.com/SufyaanKhateeb/college-placement-app-api/testdb"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+func TestMain(m *testing.M) {
+	testdb.Main(m)
+}
+
+func TestStore(t *testing.T) {
+	storetest.UserStore(t, func(t *testing.T) types.UserStore {
+		return NewStore(testdb.New(t))
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	storetest.UserStore(t, func(t *testing.T) types.UserStore {
+		return NewMemoryStore()
+	})
+}
@@ -0,0 +1,63 @@
+// Package memtx runs units of work against in-memory stores. It is apart from
+// package stores, which imports every service, so that service tests can use
+// it too.
+package memtx
+
+import (
+	"context"
+	"sync"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// snapshotter is implemented by the memory stores. Snapshot returns a
+// function that puts the store back as it was when Snapshot was called.
+type snapshotter interface {
+	Snapshot() func()
+}
+
+// Transactor runs units of work one at a time against shared memory stores.
+// A unit of work that fails or panics is rolled back by restoring the stores
+// to their state before it started. Writes made outside the transactor in the
+// meantime are rolled back with it, which tests and demo mode can live with.
+// The append-only audit and job stores are left out, other requests write to
+// them all the time and their writes must not be lost.
+type Transactor struct {
+	mu     sync.Mutex
+	stores types.Stores
+}
+
+func New(stores types.Stores) *Transactor {
+	return &Transactor{
+		stores: stores,
+	}
+}
+
+func (t *Transactor) InTx(ctx context.Context, fn func(s types.Stores) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var restores []func()
+	for _, s := range []any{t.stores.User, t.stores.Auth, t.stores.Calendar, t.stores.Invite, t.stores.Placement} {
+		if s, ok := s.(snapshotter); ok {
+			restores = append(restores, s.Snapshot())
+		}
+	}
+	rollback := func() {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			rollback()
+			panic(p)
+		}
+	}()
+	if err := fn(t.stores); err != nil {
+		rollback()
+		return err
+	}
+	return nil
+}
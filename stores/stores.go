@@ -0,0 +1,61 @@
+// Package stores assembles the Postgres and in-memory store sets and runs
+// units of work across them.
+package stores
+
+import (
+	"context"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/db"
+	"github.com/SufyaanKhateeb/college-placement-app-api/jobs"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/audit"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/auth"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/calendar"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/invite"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/placement"
+	"github.com/SufyaanKhateeb/college-placement-app-api/service/user"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+	"github.com/jackc/pgx/v5"
+)
+
+// Postgres returns stores that run their queries on q, a pool or a
+// transaction.
+func Postgres(q db.Querier) types.Stores {
+	return types.Stores{
+		User:      user.NewStore(q),
+		Auth:      auth.NewAuthStore(q),
+		Calendar:  calendar.NewStore(q),
+		Audit:     audit.NewStore(q),
+		Job:       jobs.NewStore(q),
+		Invite:    invite.NewStore(q),
+		Placement: placement.NewStore(q),
+	}
+}
+
+func Memory() types.Stores {
+	users := user.NewMemoryStore()
+	return types.Stores{
+		User:      users,
+		Auth:      auth.NewMemoryStore(users),
+		Calendar:  calendar.NewMemoryStore(users),
+		Audit:     audit.NewMemoryStore(),
+		Job:       jobs.NewMemoryStore(),
+		Invite:    invite.NewMemoryStore(),
+		Placement: placement.NewMemoryStore(),
+	}
+}
+
+type PostgresTransactor struct {
+	tm *db.TxManager
+}
+
+func NewPostgresTransactor(pool db.TxBeginner) *PostgresTransactor {
+	return &PostgresTransactor{
+		tm: db.NewTxManager(pool),
+	}
+}
+
+func (t *PostgresTransactor) InTx(ctx context.Context, fn func(s types.Stores) error) error {
+	return t.tm.InTx(ctx, func(tx pgx.Tx) error {
+		return fn(Postgres(tx))
+	})
+}
@@ -0,0 +1,272 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/stores/memtx"
+	"github.com/SufyaanKhateeb/college-placement-app-api/testdb"
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+func TestMain(m *testing.M) {
+	testdb.Main(m)
+}
+
+func newUser(email string) types.User {
+	return types.User{
+		FirstName: "fname",
+		LastName:  "lname",
+		Email:     email,
+		Password:  "hash",
+		UType:     types.UTypeStudent,
+	}
+}
+
+func exists(t *testing.T, s types.Stores, email string) bool {
+	t.Helper()
+	ok, err := s.User.CheckUserWithEmailExits(context.Background(), email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ok
+}
+
+func TestPostgresTransactor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("commits", func(t *testing.T) {
+		t.Parallel()
+		pool := testdb.New(t)
+		tr := NewPostgresTransactor(pool)
+
+		err := tr.InTx(ctx, func(s types.Stores) error {
+			id, err := s.User.CreateUser(ctx, newUser("a@example.com"))
+			if err != nil {
+				return err
+			}
+			return s.Calendar.CreateFeedToken(ctx, id, "hash")
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !exists(t, Postgres(pool), "a@example.com") {
+			t.Fatal("expected the user to be committed")
+		}
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		t.Parallel()
+		pool := testdb.New(t)
+		tr := NewPostgresTransactor(pool)
+
+		fnErr := errors.New("boom")
+		err := tr.InTx(ctx, func(s types.Stores) error {
+			id, err := s.User.CreateUser(ctx, newUser("a@example.com"))
+			if err != nil {
+				return err
+			}
+			// nested transactions become savepoints and are undone as well
+			if err := s.Calendar.CreateFeedToken(ctx, id, "hash"); err != nil {
+				return err
+			}
+			return fnErr
+		})
+		if !errors.Is(err, fnErr) {
+			t.Fatalf("expected %v, got %v", fnErr, err)
+		}
+		if exists(t, Postgres(pool), "a@example.com") {
+			t.Fatal("expected the user to be rolled back")
+		}
+		if _, err := Postgres(pool).Calendar.GetUserIdByFeedToken(ctx, "hash"); err == nil {
+			t.Fatal("expected the feed token to be rolled back")
+		}
+	})
+
+	t.Run("rolls back on panic", func(t *testing.T) {
+		t.Parallel()
+		pool := testdb.New(t)
+		tr := NewPostgresTransactor(pool)
+
+		func() {
+			defer func() {
+				if p := recover(); p != "boom" {
+					t.Fatalf("expected the panic to be re-raised, got %v", p)
+				}
+			}()
+			tr.InTx(ctx, func(s types.Stores) error {
+				if _, err := s.User.CreateUser(ctx, newUser("a@example.com")); err != nil {
+					return err
+				}
+				panic("boom")
+			})
+		}()
+
+		if exists(t, Postgres(pool), "a@example.com") {
+			t.Fatal("expected the user to be rolled back")
+		}
+	})
+
+	t.Run("retries serialization failures", func(t *testing.T) {
+		t.Parallel()
+		pool := testdb.New(t)
+		tr := NewPostgresTransactor(pool)
+
+		// both transactions see the email as free before either inserts it,
+		// the loser is retried and then finds it taken
+		errTaken := errors.New("email taken")
+		var checked sync.WaitGroup
+		checked.Add(2)
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				attempt := 0
+				errs[i] = tr.InTx(ctx, func(s types.Stores) error {
+					attempt++
+					ok, err := s.User.CheckUserWithEmailExits(ctx, "race@example.com")
+					if err != nil {
+						return err
+					}
+					if attempt == 1 {
+						checked.Done()
+						checked.Wait()
+					}
+					if ok {
+						return errTaken
+					}
+					_, err = s.User.CreateUser(ctx, newUser("race@example.com"))
+					return err
+				})
+			}()
+		}
+		wg.Wait()
+
+		taken := 0
+		for _, err := range errs {
+			switch {
+			case errors.Is(err, errTaken):
+				taken++
+			case err != nil:
+				t.Fatalf("unexpected error %v", err)
+			}
+		}
+		if taken != 1 {
+			t.Fatalf("expected exactly one transaction to find the email taken, got %v", errs)
+		}
+	})
+}
+
+func TestMemoryTransactor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("commits", func(t *testing.T) {
+		t.Parallel()
+		stores := Memory()
+		tr := memtx.New(stores)
+
+		err := tr.InTx(ctx, func(s types.Stores) error {
+			_, err := s.User.CreateUser(ctx, newUser("a@example.com"))
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !exists(t, stores, "a@example.com") {
+			t.Fatal("expected the user to be stored")
+		}
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		t.Parallel()
+		stores := Memory()
+		tr := memtx.New(stores)
+		keptId, err := stores.User.CreateUser(ctx, newUser("kept@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fnErr := errors.New("boom")
+		err = tr.InTx(ctx, func(s types.Stores) error {
+			id, err := s.User.CreateUser(ctx, newUser("a@example.com"))
+			if err != nil {
+				return err
+			}
+			if err := s.Calendar.CreateFeedToken(ctx, id, "hash"); err != nil {
+				return err
+			}
+			if err := s.User.SetUserDisabled(ctx, keptId, true); err != nil {
+				return err
+			}
+			return fnErr
+		})
+		if !errors.Is(err, fnErr) {
+			t.Fatalf("expected %v, got %v", fnErr, err)
+		}
+		if exists(t, stores, "a@example.com") {
+			t.Fatal("expected the user to be rolled back")
+		}
+		if _, err := stores.Calendar.GetUserIdByFeedToken(ctx, "hash"); err == nil {
+			t.Fatal("expected the feed token to be rolled back")
+		}
+		if u, err := stores.User.GetUserById(ctx, keptId); err != nil || u.DisabledAt != nil {
+			t.Fatalf("expected the update to be rolled back, got %+v (%v)", u, err)
+		}
+	})
+
+	t.Run("rolls back on panic", func(t *testing.T) {
+		t.Parallel()
+		stores := Memory()
+		tr := memtx.New(stores)
+
+		func() {
+			defer func() {
+				if p := recover(); p != "boom" {
+					t.Fatalf("expected the panic to be re-raised, got %v", p)
+				}
+			}()
+			tr.InTx(ctx, func(s types.Stores) error {
+				if _, err := s.User.CreateUser(ctx, newUser("a@example.com")); err != nil {
+					return err
+				}
+				panic("boom")
+			})
+		}()
+
+		if exists(t, stores, "a@example.com") {
+			t.Fatal("expected the user to be rolled back")
+		}
+	})
+
+	t.Run("keeps audit events and jobs", func(t *testing.T) {
+		t.Parallel()
+		stores := Memory()
+		tr := memtx.New(stores)
+
+		fnErr := errors.New("boom")
+		err := tr.InTx(ctx, func(s types.Stores) error {
+			// another request logs and enqueues while this one runs
+			if err := stores.Audit.AppendAuditEvent(ctx, types.AuditEvent{Action: "POST /api/v1/login"}); err != nil {
+				return err
+			}
+			if _, err := stores.Job.EnqueueJob(ctx, types.Job{Kind: "email", MaxAttempts: 1, RunAt: time.Now()}); err != nil {
+				return err
+			}
+			return fnErr
+		})
+		if !errors.Is(err, fnErr) {
+			t.Fatalf("expected %v, got %v", fnErr, err)
+		}
+		if events, err := stores.Audit.ListAuditEvents(ctx, types.AuditEventFilter{}); err != nil || len(events) != 1 {
+			t.Errorf("expected the audit event to be kept, got %v (%v)", events, err)
+		}
+		if job, err := stores.Job.ClaimJob(ctx, []string{"email"}, time.Now()); err != nil || job == nil {
+			t.Errorf("expected the job to be kept, got %v (%v)", job, err)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// AuditStore runs the conformance suite against empty stores from newStore.
+func AuditStore(t *testing.T, newStore func(t *testing.T) types.AuditStore) {
+	ctx := context.Background()
+
+	appendEvents := func(t *testing.T, store types.AuditStore) {
+		events := []types.AuditEvent{
+			{ActorId: 1, ActorRole: types.UTypeAdmin, Action: "POST /api/v1/register", EntityType: "user", EntityId: "1", Status: 201, Changes: []byte(`{"email":{"new":"a@example.com"}}`)},
+			{ActorId: 2, Action: "POST /api/v1/login", EntityType: "user", EntityId: "2", Status: 200},
+			{ActorId: 1, Action: "DELETE /api/v1/calendar/feed", Status: 204},
+		}
+		for _, e := range events {
+			if err := store.AppendAuditEvent(ctx, e); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	t.Run("lists newest first with an intact chain", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+		appendEvents(t, store)
+
+		events, err := store.ListAuditEvents(ctx, types.AuditEventFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 3 {
+			t.Fatalf("expected 3 events, got %d", len(events))
+		}
+		if events[0].Action != "DELETE /api/v1/calendar/feed" || events[0].Id <= events[1].Id {
+			t.Errorf("expected newest event first, got %+v", events[0])
+		}
+		if events[2].PrevHash == "" || events[1].PrevHash != events[2].Hash || events[0].PrevHash != events[1].Hash {
+			t.Error("expected events to be chained")
+		}
+		if string(events[2].Changes) != `{"email":{"new":"a@example.com"}}` {
+			t.Errorf("expected changes to round trip, got %s", events[2].Changes)
+		}
+
+		broken, err := store.VerifyAuditChain(ctx)
+		if err != nil || broken != 0 {
+			t.Errorf("expected intact chain, got %d (%v)", broken, err)
+		}
+	})
+
+	t.Run("filters and paginates", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+		appendEvents(t, store)
+
+		tests := []struct {
+			name   string
+			filter types.AuditEventFilter
+			want   int
+		}{
+			{name: "actor", filter: types.AuditEventFilter{ActorId: 1}, want: 2},
+			{name: "action", filter: types.AuditEventFilter{Action: "POST /api/v1/login"}, want: 1},
+			{name: "entity", filter: types.AuditEventFilter{EntityType: "user", EntityId: "1"}, want: 1},
+			{name: "limit", filter: types.AuditEventFilter{Limit: 2}, want: 2},
+			{name: "offset", filter: types.AuditEventFilter{Offset: 2}, want: 1},
+			{name: "from", filter: types.AuditEventFilter{From: time.Now().Add(time.Hour)}, want: 0},
+			{name: "to", filter: types.AuditEventFilter{To: time.Now().Add(time.Hour)}, want: 3},
+		}
+		for _, tt := range tests {
+			events, err := store.ListAuditEvents(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != tt.want {
+				t.Errorf("%s: expected %d events, got %d", tt.name, tt.want, len(events))
+			}
+		}
+	})
+
+	t.Run("each visits the listed events", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+		appendEvents(t, store)
+
+		filter := types.AuditEventFilter{ActorId: 1}
+		want, err := store.ListAuditEvents(ctx, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []types.AuditEvent
+		err = store.EachAuditEvent(ctx, filter, func(e types.AuditEvent) error {
+			got = append(got, e)
+			return nil
+		})
+		if err != nil || len(got) != len(want) {
+			t.Fatalf("expected %d events, got %d (%v)", len(want), len(got), err)
+		}
+		for i := range got {
+			if got[i].Id != want[i].Id || got[i].Hash != want[i].Hash {
+				t.Errorf("expected %+v at %d, got %+v", want[i], i, got[i])
+			}
+		}
+
+		stop := errors.New("stop")
+		visited := 0
+		err = store.EachAuditEvent(ctx, types.AuditEventFilter{}, func(e types.AuditEvent) error {
+			visited++
+			return stop
+		})
+		if !errors.Is(err, stop) || visited != 1 {
+			t.Errorf("expected to stop at the first error, visited %d (%v)", visited, err)
+		}
+	})
+
+	t.Run("empty store", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		events, err := store.ListAuditEvents(ctx, types.AuditEventFilter{})
+		if err != nil || events == nil || len(events) != 0 {
+			t.Errorf("expected an empty, non nil list, got %v (%v)", events, err)
+		}
+		if broken, err := store.VerifyAuditChain(ctx); err != nil || broken != 0 {
+			t.Errorf("expected intact chain, got %d (%v)", broken, err)
+		}
+	})
+}
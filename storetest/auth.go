@@ -0,0 +1,164 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// AuthStore runs the conformance suite. Sessions belong to users, so
+// newStores returns a user store backed by the same database.
+func AuthStore(t *testing.T, newStores func(t *testing.T) (types.AuthStore, types.UserStore)) {
+	ctx := context.Background()
+	longAgo := time.Now().Add(-time.Hour)
+
+	newUsers := func(t *testing.T, users types.UserStore, emails ...string) []int {
+		t.Helper()
+		var ids []int
+		for _, email := range emails {
+			id, err := users.CreateUser(ctx, newUser(email))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	t.Run("create and get session", func(t *testing.T) {
+		t.Parallel()
+		store, users := newStores(t)
+		userId := newUsers(t, users, "a@example.com")[0]
+
+		id, err := store.CreateSession(ctx, types.Session{UserId: userId, UserAgent: "Firefox", Ip: "10.0.0.1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := store.GetSession(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Id != id || s.UserId != userId || s.UserAgent != "Firefox" || s.Ip != "10.0.0.1" ||
+			s.CreatedAt.IsZero() || s.LastSeenAt.IsZero() || s.RevokedAt != nil {
+			t.Errorf("unexpected session %+v", s)
+		}
+
+		if _, err := store.GetSession(ctx, id+100); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("touch session", func(t *testing.T) {
+		t.Parallel()
+		store, users := newStores(t)
+		userId := newUsers(t, users, "a@example.com")[0]
+
+		id, err := store.CreateSession(ctx, types.Session{UserId: userId})
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := time.Now().Add(time.Hour).Truncate(time.Second)
+		if err := store.TouchSession(ctx, id, seen); err != nil {
+			t.Fatal(err)
+		}
+		s, err := store.GetSession(ctx, id)
+		if err != nil || !s.LastSeenAt.Equal(seen) {
+			t.Errorf("expected last seen %v, got %+v (%v)", seen, s, err)
+		}
+		if err := store.TouchSession(ctx, id+100, seen); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("list and revoke sessions", func(t *testing.T) {
+		t.Parallel()
+		store, users := newStores(t)
+		ids := newUsers(t, users, "a@example.com", "b@example.com")
+		alice, bob := ids[0], ids[1]
+
+		var sessions []int
+		for _, userId := range []int{alice, alice, alice, bob} {
+			id, err := store.CreateSession(ctx, types.Session{UserId: userId})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sessions = append(sessions, id)
+		}
+
+		list, err := store.ListSessions(ctx, alice, longAgo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 3 || list[0].Id != sessions[2] || list[2].Id != sessions[0] {
+			t.Fatalf("expected alice's sessions newest first, got %+v", list)
+		}
+		if list, err := store.ListSessions(ctx, alice, time.Now().Add(time.Hour)); err != nil || len(list) != 0 {
+			t.Errorf("expected sessions created before since to be left out, got %+v (%v)", list, err)
+		}
+
+		// users can only revoke their own sessions, and only once
+		if err := store.RevokeSession(ctx, bob, sessions[0]); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for another user's session, got %v", err)
+		}
+		if err := store.RevokeSession(ctx, alice, sessions[0]); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.RevokeSession(ctx, alice, sessions[0]); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a revoked session, got %v", err)
+		}
+		s, err := store.GetSession(ctx, sessions[0])
+		if err != nil || s.RevokedAt == nil {
+			t.Errorf("expected a revoked session, got %+v (%v)", s, err)
+		}
+
+		if err := store.RevokeSessions(ctx, alice); err != nil {
+			t.Fatal(err)
+		}
+		if list, err := store.ListSessions(ctx, alice, longAgo); err != nil || len(list) != 0 {
+			t.Errorf("expected no active sessions, got %+v (%v)", list, err)
+		}
+		if list, err := store.ListSessions(ctx, bob, longAgo); err != nil || len(list) != 1 {
+			t.Errorf("expected bob's session to survive, got %+v (%v)", list, err)
+		}
+	})
+
+	t.Run("sessions of disabled or deleted users are not found", func(t *testing.T) {
+		t.Parallel()
+		store, users := newStores(t)
+		ids := newUsers(t, users, "a@example.com", "b@example.com", "c@example.com")
+
+		var sessions []int
+		for _, userId := range ids {
+			id, err := store.CreateSession(ctx, types.Session{UserId: userId})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sessions = append(sessions, id)
+		}
+		if err := users.SetUserDisabled(ctx, ids[0], true); err != nil {
+			t.Fatal(err)
+		}
+		if err := users.DeleteUser(ctx, ids[1]); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, id := range sessions[:2] {
+			if s, err := store.GetSession(ctx, id); !errors.Is(err, types.ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %+v (%v)", s, err)
+			}
+		}
+		if _, err := store.GetSession(ctx, sessions[2]); err != nil {
+			t.Errorf("expected the active user's session, got %v", err)
+		}
+
+		if err := users.SetUserDisabled(ctx, ids[0], false); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.GetSession(ctx, sessions[0]); err != nil {
+			t.Errorf("expected the session to work again once the user is enabled, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// CalendarStore runs the conformance suite. Feeds belong to users, so
+// newStores returns a user store backed by the same database.
+func CalendarStore(t *testing.T, newStores func(t *testing.T) (types.CalendarStore, types.UserStore)) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (types.CalendarStore, int, int) {
+		store, users := newStores(t)
+		first, err := users.CreateUser(ctx, newUser("first@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := users.CreateUser(ctx, newUser("second@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store, first, second
+	}
+
+	t.Run("resolves token to its user", func(t *testing.T) {
+		t.Parallel()
+		store, first, second := setup(t)
+
+		if err := store.CreateFeedToken(ctx, first, "hash-1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.CreateFeedToken(ctx, second, "hash-2"); err != nil {
+			t.Fatal(err)
+		}
+
+		for hash, want := range map[string]int{"hash-1": first, "hash-2": second} {
+			got, err := store.GetUserIdByFeedToken(ctx, hash)
+			if err != nil || got != want {
+				t.Errorf("expected user %d for %s, got %d (%v)", want, hash, got, err)
+			}
+		}
+		if _, err := store.GetUserIdByFeedToken(ctx, "unknown"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for an unknown token, got %v", err)
+		}
+	})
+
+	t.Run("new token replaces the previous one", func(t *testing.T) {
+		t.Parallel()
+		store, first, _ := setup(t)
+
+		if err := store.CreateFeedToken(ctx, first, "old"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.CreateFeedToken(ctx, first, "new"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := store.GetUserIdByFeedToken(ctx, "old"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected old token to be revoked, got %v", err)
+		}
+		if _, err := store.GetUserIdByFeedToken(ctx, "new"); err != nil {
+			t.Errorf("expected new token to work, got %v", err)
+		}
+	})
+
+	t.Run("revoke only affects the user", func(t *testing.T) {
+		t.Parallel()
+		store, first, second := setup(t)
+
+		if err := store.CreateFeedToken(ctx, first, "hash-1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.CreateFeedToken(ctx, second, "hash-2"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.RevokeFeedTokens(ctx, first); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := store.GetUserIdByFeedToken(ctx, "hash-1"); err == nil {
+			t.Error("expected revoked token to fail")
+		}
+		if _, err := store.GetUserIdByFeedToken(ctx, "hash-2"); err != nil {
+			t.Errorf("expected other user's token to work, got %v", err)
+		}
+	})
+
+	t.Run("feeds of disabled or deleted users are not found", func(t *testing.T) {
+		t.Parallel()
+		store, users := newStores(t)
+		var ids []int
+		for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+			id, err := users.CreateUser(ctx, newUser(email))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := store.CreateFeedToken(ctx, id, email); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+		}
+		if err := users.SetUserDisabled(ctx, ids[0], true); err != nil {
+			t.Fatal(err)
+		}
+		if err := users.DeleteUser(ctx, ids[1]); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, hash := range []string{"a@example.com", "b@example.com"} {
+			if id, err := store.GetUserIdByFeedToken(ctx, hash); !errors.Is(err, types.ErrNotFound) {
+				t.Errorf("expected ErrNotFound for %s, got %d (%v)", hash, id, err)
+			}
+		}
+		if _, err := store.GetUserIdByFeedToken(ctx, "c@example.com"); err != nil {
+			t.Errorf("expected the active user's feed to work, got %v", err)
+		}
+
+		if err := users.SetUserDisabled(ctx, ids[0], false); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.GetUserIdByFeedToken(ctx, "a@example.com"); err != nil {
+			t.Errorf("expected the feed to work again once enabled, got %v", err)
+		}
+	})
+}
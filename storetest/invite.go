@@ -0,0 +1,211 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// InviteStore runs the conformance suite. Accepted invites point at a user,
+// so newStores returns a user store backed by the same database.
+func InviteStore(t *testing.T, newStores func(t *testing.T) (types.InviteStore, types.UserStore)) {
+	ctx := context.Background()
+	later := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	newInvite := func(email string, expiresAt time.Time) types.Invite {
+		return types.Invite{
+			Email:     email,
+			UType:     types.UTypeRecruiter,
+			Company:   "Acme",
+			CreatedBy: 1,
+			ExpiresAt: expiresAt,
+		}
+	}
+
+	t.Run("create and get invite", func(t *testing.T) {
+		t.Parallel()
+		store, _ := newStores(t)
+
+		id, err := store.CreateInvite(ctx, newInvite("a@example.com", later))
+		if err != nil {
+			t.Fatal(err)
+		}
+		inv, err := store.GetInvite(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inv.Id != id || inv.Email != "a@example.com" || inv.UType != types.UTypeRecruiter || inv.Company != "Acme" ||
+			inv.CreatedBy != 1 || inv.SendCount != 1 || !inv.ExpiresAt.Equal(later) || inv.CreatedAt.IsZero() ||
+			inv.LastSentAt.IsZero() || inv.Status(time.Now()) != types.InviteStatusPending {
+			t.Errorf("unexpected invite %+v", inv)
+		}
+
+		if _, err := store.GetInvite(ctx, id+100); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("renew moves expiry and counts sends", func(t *testing.T) {
+		t.Parallel()
+		store, _ := newStores(t)
+
+		id, err := store.CreateInvite(ctx, newInvite("a@example.com", time.Now().Add(-time.Minute)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.RenewInvite(ctx, id, later); err != nil {
+			t.Fatal(err)
+		}
+		inv, err := store.GetInvite(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inv.SendCount != 2 || !inv.ExpiresAt.Equal(later) || inv.Status(time.Now()) != types.InviteStatusPending {
+			t.Errorf("expected a renewed pending invite, got %+v", inv)
+		}
+
+		if err := store.RenewInvite(ctx, id+100, later); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("accept", func(t *testing.T) {
+		t.Parallel()
+		store, users := newStores(t)
+
+		userId, err := users.CreateUser(ctx, newUser("a@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := store.CreateInvite(ctx, newInvite("a@example.com", later))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.AcceptInvite(ctx, id, userId); err != nil {
+			t.Fatal(err)
+		}
+		inv, err := store.GetInvite(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inv.AcceptedAt == nil || inv.AcceptedUserId != userId || inv.Status(time.Now()) != types.InviteStatusAccepted {
+			t.Errorf("expected an accepted invite, got %+v", inv)
+		}
+
+		for name, err := range map[string]error{
+			"accept":  store.AcceptInvite(ctx, id, userId),
+			"renew":   store.RenewInvite(ctx, id, later),
+			"revoke":  store.RevokeInvite(ctx, id),
+			"missing": store.AcceptInvite(ctx, id+100, userId),
+		} {
+			want := types.ErrInviteClosed
+			if name == "missing" {
+				want = types.ErrNotFound
+			}
+			if !errors.Is(err, want) {
+				t.Errorf("%s: expected %v, got %v", name, want, err)
+			}
+		}
+	})
+
+	t.Run("expired invites cannot be accepted", func(t *testing.T) {
+		t.Parallel()
+		store, users := newStores(t)
+
+		userId, err := users.CreateUser(ctx, newUser("a@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := store.CreateInvite(ctx, newInvite("a@example.com", time.Now().Add(-time.Minute)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.AcceptInvite(ctx, id, userId); !errors.Is(err, types.ErrInviteClosed) {
+			t.Errorf("expected ErrInviteClosed, got %v", err)
+		}
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		t.Parallel()
+		store, users := newStores(t)
+
+		userId, err := users.CreateUser(ctx, newUser("a@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := store.CreateInvite(ctx, newInvite("a@example.com", later))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.RevokeInvite(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+		inv, err := store.GetInvite(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inv.RevokedAt == nil || inv.Status(time.Now()) != types.InviteStatusRevoked {
+			t.Errorf("expected a revoked invite, got %+v", inv)
+		}
+		if err := store.AcceptInvite(ctx, id, userId); !errors.Is(err, types.ErrInviteClosed) {
+			t.Errorf("expected ErrInviteClosed, got %v", err)
+		}
+	})
+
+	t.Run("list filters by status and email", func(t *testing.T) {
+		t.Parallel()
+		store, _ := newStores(t)
+
+		var ids []int
+		for _, inv := range []types.Invite{
+			newInvite("pending@example.com", later),
+			newInvite("expired@example.com", time.Now().Add(-time.Minute)),
+			newInvite("revoked@example.com", later),
+			newInvite("pending@example.com", later),
+		} {
+			id, err := store.CreateInvite(ctx, inv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+		}
+		if err := store.RevokeInvite(ctx, ids[2]); err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			filter types.InviteFilter
+			want   []int
+		}{
+			{types.InviteFilter{}, []int{ids[3], ids[2], ids[1], ids[0]}},
+			{types.InviteFilter{Status: types.InviteStatusPending}, []int{ids[3], ids[0]}},
+			{types.InviteFilter{Status: types.InviteStatusExpired}, []int{ids[1]}},
+			{types.InviteFilter{Status: types.InviteStatusRevoked}, []int{ids[2]}},
+			{types.InviteFilter{Email: "Pending@Example.com"}, []int{ids[3], ids[0]}},
+			{types.InviteFilter{Limit: 2, Offset: 1}, []int{ids[2], ids[1]}},
+		}
+		for _, tt := range tests {
+			invites, err := store.ListInvites(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, inv := range invites {
+				got = append(got, inv.Id)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("%+v: expected %v, got %v", tt.filter, tt.want, got)
+				continue
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("%+v: expected %v, got %v", tt.filter, tt.want, got)
+					break
+				}
+			}
+		}
+	})
+}
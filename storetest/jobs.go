@@ -0,0 +1,110 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// JobStore runs the conformance suite against empty stores from newStore.
+func JobStore(t *testing.T, newStore func(t *testing.T) types.JobStore) {
+	ctx := context.Background()
+
+	enqueue := func(t *testing.T, store types.JobStore, job types.Job) int64 {
+		if job.MaxAttempts == 0 {
+			job.MaxAttempts = 3
+		}
+		if job.Payload == nil {
+			job.Payload = []byte(`{}`)
+		}
+		id, err := store.EnqueueJob(ctx, job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	past := time.Now().Add(-time.Minute)
+
+	t.Run("unique key is enqueued once", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		if id := enqueue(t, store, types.Job{Kind: "a", RunAt: past, UniqueKey: "a@1"}); id == 0 {
+			t.Fatal("expected an id")
+		}
+		if id := enqueue(t, store, types.Job{Kind: "a", RunAt: past, UniqueKey: "a@1"}); id != 0 {
+			t.Errorf("expected duplicate to be skipped, got id %d", id)
+		}
+	})
+
+	t.Run("claims due jobs of the given kinds once", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		enqueue(t, store, types.Job{Kind: "other", RunAt: past})
+		enqueue(t, store, types.Job{Kind: "a", RunAt: time.Now().Add(time.Hour)})
+		due := enqueue(t, store, types.Job{Kind: "a", RunAt: past, Payload: []byte(`{"n":1}`)})
+
+		job, err := store.ClaimJob(ctx, []string{"a"}, past)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job == nil || job.Id != due {
+			t.Fatalf("expected job %d, got %+v", due, job)
+		}
+		if job.Status != "running" || job.Attempts != 1 || string(job.Payload) != `{"n":1}` {
+			t.Errorf("unexpected claimed job %+v", job)
+		}
+
+		again, err := store.ClaimJob(ctx, []string{"a"}, past)
+		if err != nil || again != nil {
+			t.Errorf("expected no more work, got %+v (%v)", again, err)
+		}
+
+		// a worker that never finished is considered dead once its lock is stale
+		stale, err := store.ClaimJob(ctx, []string{"a"}, time.Now().Add(time.Minute))
+		if err != nil || stale == nil || stale.Id != due || stale.Attempts != 2 {
+			t.Errorf("expected stale job to be reclaimed, got %+v (%v)", stale, err)
+		}
+	})
+
+	t.Run("retry, fail and complete", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		id := enqueue(t, store, types.Job{Kind: "a", RunAt: past})
+		if _, err := store.ClaimJob(ctx, []string{"a"}, past); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.RetryJob(ctx, id, past, "boom"); err != nil {
+			t.Fatal(err)
+		}
+
+		job, err := store.ClaimJob(ctx, []string{"a"}, past)
+		if err != nil || job == nil || job.Attempts != 2 {
+			t.Fatalf("expected retried job to be claimable, got %+v (%v)", job, err)
+		}
+		if err := store.FailJob(ctx, id, "boom again"); err != nil {
+			t.Fatal(err)
+		}
+		if job, _ := store.ClaimJob(ctx, []string{"a"}, time.Now().Add(time.Minute)); job != nil {
+			t.Errorf("expected failed job to stay put, got %+v", job)
+		}
+
+		done := enqueue(t, store, types.Job{Kind: "a", RunAt: past})
+		if _, err := store.ClaimJob(ctx, []string{"a"}, past); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.CompleteJob(ctx, done); err != nil {
+			t.Fatal(err)
+		}
+		enqueue(t, store, types.Job{Kind: "a", RunAt: time.Now().Add(time.Hour)})
+
+		n, err := store.DeleteFinishedJobs(ctx, time.Now().Add(time.Minute))
+		if err != nil || n != 2 {
+			t.Errorf("expected the failed and succeeded jobs to be deleted, got %d (%v)", n, err)
+		}
+	})
+}
@@ -0,0 +1,270 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+// PlacementStore runs the conformance suite. Applications belong to
+// students, so newStores returns a user store backed by the same database.
+func PlacementStore(t *testing.T, newStores func(t *testing.T) (types.PlacementStore, types.UserStore)) {
+	ctx := context.Background()
+	deadline := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+
+	setup := func(t *testing.T) (types.PlacementStore, types.UserStore, int) {
+		store, users := newStores(t)
+		companyId, err := store.CreateCompany(ctx, types.Company{Name: "Acme", Domain: "acme.example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store, users, companyId
+	}
+
+	newStudent := func(t *testing.T, users types.UserStore, email string) int {
+		u := newUser(email)
+		u.UType, u.Company, u.Branch = types.UTypeStudent, "", "CSE"
+		id, err := users.CreateUser(ctx, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+
+	driveIds := func(drives []types.Drive) []int {
+		ids := []int{}
+		for _, d := range drives {
+			ids = append(ids, d.Id)
+		}
+		return ids
+	}
+
+	t.Run("lists drives eligible for a branch", func(t *testing.T) {
+		t.Parallel()
+		store, _, companyId := setup(t)
+
+		talkAt := deadline.Add(-time.Hour)
+		open, err := store.CreateDrive(ctx, types.Drive{
+			CompanyId:           companyId,
+			Title:               "Open",
+			ApplicationDeadline: deadline.Add(time.Hour),
+			TalkAt:              &talkAt,
+			TalkLocation:        "Seminar hall",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cse, err := store.CreateDrive(ctx, types.Drive{
+			CompanyId:           companyId,
+			Title:               "CSE only",
+			EligibleBranches:    []string{"CSE", "IT"},
+			ApplicationDeadline: deadline,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.CreateDrive(ctx, types.Drive{CompanyId: companyId + 100, Title: "x", ApplicationDeadline: deadline}); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a missing company, got %v", err)
+		}
+
+		tests := []struct {
+			branch string
+			want   []int
+		}{
+			{"cse", []int{cse, open}},
+			{"MECH", []int{open}},
+			{"", []int{open}},
+		}
+		for _, tt := range tests {
+			drives, err := store.ListEligibleDrives(ctx, tt.branch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := driveIds(drives); !slices.Equal(got, tt.want) {
+				t.Errorf("%q: expected %v, got %v", tt.branch, tt.want, got)
+			}
+		}
+
+		drives, err := store.ListEligibleDrives(ctx, "MECH")
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := drives[0]
+		if d.CompanyId != companyId || d.CompanyName != "Acme" || d.Title != "Open" || !d.ApplicationDeadline.Equal(deadline.Add(time.Hour)) ||
+			d.TalkAt == nil || !d.TalkAt.Equal(talkAt) || d.TalkLocation != "Seminar hall" || len(d.EligibleBranches) != 0 || d.CreatedAt.IsZero() {
+			t.Errorf("unexpected drive %+v", d)
+		}
+	})
+
+	t.Run("students apply once per drive", func(t *testing.T) {
+		t.Parallel()
+		store, users, companyId := setup(t)
+		student := newStudent(t, users, "student@example.com")
+
+		driveId, err := store.CreateDrive(ctx, types.Drive{CompanyId: companyId, Title: "SDE", ApplicationDeadline: deadline})
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := store.CreateApplication(ctx, types.Application{DriveId: driveId, StudentId: student})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.CreateApplication(ctx, types.Application{DriveId: driveId, StudentId: student}); !errors.Is(err, types.ErrAlreadyApplied) {
+			t.Errorf("expected ErrAlreadyApplied, got %v", err)
+		}
+		if _, err := store.CreateApplication(ctx, types.Application{DriveId: driveId + 100, StudentId: student}); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a missing drive, got %v", err)
+		}
+
+		if err := store.SetApplicationStatus(ctx, id, types.ApplicationStatusShortlisted); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SetApplicationStatus(ctx, id+100, types.ApplicationStatusShortlisted); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("lists the interviews of a student", func(t *testing.T) {
+		t.Parallel()
+		store, users, companyId := setup(t)
+		student := newStudent(t, users, "student@example.com")
+		other := newStudent(t, users, "other@example.com")
+
+		driveId, err := store.CreateDrive(ctx, types.Drive{CompanyId: companyId, Title: "SDE", ApplicationDeadline: deadline})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var slots []int
+		for _, studentId := range []int{student, other} {
+			appId, err := store.CreateApplication(ctx, types.Application{DriveId: driveId, StudentId: studentId, Status: types.ApplicationStatusInterview})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, at := range []time.Time{deadline.Add(48 * time.Hour), deadline.Add(24 * time.Hour)} {
+				id, err := store.CreateInterviewSlot(ctx, types.InterviewSlot{ApplicationId: appId, StartsAt: at, EndsAt: at.Add(30 * time.Minute), Location: "Room 4"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				slots = append(slots, id)
+			}
+		}
+		if _, err := store.CreateInterviewSlot(ctx, types.InterviewSlot{ApplicationId: 1000, StartsAt: deadline, EndsAt: deadline}); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a missing application, got %v", err)
+		}
+
+		interviews, err := store.ListInterviews(ctx, student)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(interviews) != 2 || interviews[0].Id != slots[1] || interviews[1].Id != slots[0] {
+			t.Fatalf("expected slots %v sorted by start, got %+v", slots[:2], interviews)
+		}
+		i := interviews[0]
+		if i.DriveId != driveId || i.DriveTitle != "SDE" || i.CompanyName != "Acme" || i.Location != "Room 4" ||
+			!i.StartsAt.Equal(deadline.Add(24*time.Hour)) || !i.EndsAt.Equal(deadline.Add(24*time.Hour+30*time.Minute)) {
+			t.Errorf("unexpected interview %+v", i)
+		}
+	})
+
+	t.Run("applications and offers of a student", func(t *testing.T) {
+		t.Parallel()
+		store, users, companyId := setup(t)
+		student := newStudent(t, users, "student@example.com")
+		other := newStudent(t, users, "other@example.com")
+
+		var apps []int
+		for _, title := range []string{"SDE", "Analyst"} {
+			driveId, err := store.CreateDrive(ctx, types.Drive{CompanyId: companyId, Title: title, ApplicationDeadline: deadline})
+			if err != nil {
+				t.Fatal(err)
+			}
+			id, err := store.CreateApplication(ctx, types.Application{DriveId: driveId, StudentId: student})
+			if err != nil {
+				t.Fatal(err)
+			}
+			apps = append(apps, id)
+			if _, err := store.CreateApplication(ctx, types.Application{DriveId: driveId, StudentId: other}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := store.SetApplicationStatus(ctx, apps[1], types.ApplicationStatusOffered); err != nil {
+			t.Fatal(err)
+		}
+		offerId, err := store.CreateOffer(ctx, types.Offer{ApplicationId: apps[1], Ctc: 1200000})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.CreateOffer(ctx, types.Offer{ApplicationId: 1000, Ctc: 1}); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a missing application, got %v", err)
+		}
+
+		applications, err := store.ListApplications(ctx, student)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(applications) != 2 || applications[0].Id != apps[0] || applications[1].Id != apps[1] {
+			t.Fatalf("expected applications %v, got %+v", apps, applications)
+		}
+		if a := applications[0]; a.StudentId != student || a.Status != types.ApplicationStatusApplied || a.CreatedAt.IsZero() {
+			t.Errorf("unexpected application %+v", a)
+		}
+		if applications[1].Status != types.ApplicationStatusOffered {
+			t.Errorf("expected offered status, got %s", applications[1].Status)
+		}
+
+		offers, err := store.ListOffers(ctx, student)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(offers) != 1 || offers[0].Id != offerId || offers[0].ApplicationId != apps[1] || offers[0].Ctc != 1200000 ||
+			offers[0].Status != types.OfferStatusExtended || offers[0].CreatedAt.IsZero() {
+			t.Errorf("unexpected offers %+v", offers)
+		}
+		if offers, err := store.ListOffers(ctx, other); err != nil || len(offers) != 0 {
+			t.Errorf("expected no offers for the other student, got %+v (%v)", offers, err)
+		}
+	})
+
+	t.Run("student profiles", func(t *testing.T) {
+		t.Parallel()
+		store, users, _ := setup(t)
+		student := newStudent(t, users, "student@example.com")
+
+		if _, err := store.GetStudentProfile(ctx, student); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		for _, cgpa := range []float64{7.5, 8.25} {
+			if err := store.UpsertStudentProfile(ctx, types.StudentProfile{UserId: student, Cgpa: cgpa, GraduationYear: 2025}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		p, err := store.GetStudentProfile(ctx, student)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.UserId != student || p.Cgpa != 8.25 || p.GraduationYear != 2025 || p.UpdatedAt.IsZero() {
+			t.Errorf("unexpected profile %+v", p)
+		}
+	})
+
+	t.Run("get company by domain", func(t *testing.T) {
+		t.Parallel()
+		store, _, _ := setup(t)
+
+		id, err := store.CreateCompany(ctx, types.Company{Name: "Globex", Domain: "globex.example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := store.GetCompanyByDomain(ctx, "GLOBEX.example.com")
+		if err != nil || c.Id != id || c.Name != "Globex" || c.CreatedAt.IsZero() {
+			t.Fatalf("unexpected company %+v (%v)", c, err)
+		}
+		if _, err := store.GetCompanyByDomain(ctx, "example.com"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
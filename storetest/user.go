@@ -0,0 +1,371 @@
+// Package storetest holds conformance suites every implementation of a store
+// interface must pass, so the in-memory stores keep behaving like Postgres.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/types"
+)
+
+func newUser(email string) types.User {
+	return types.User{
+		FirstName: "fname",
+		LastName:  "lname",
+		Email:     email,
+		Password:  "hash",
+		UType:     types.UTypeRecruiter,
+		Company:   "Acme",
+	}
+}
+
+// UserStore runs the conformance suite, calling newStore for an empty store in
+// every subtest.
+func UserStore(t *testing.T, newStore func(t *testing.T) types.UserStore) {
+	ctx := context.Background()
+
+	t.Run("create and get user", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		id, err := store.CreateUser(ctx, newUser("a@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id == 0 {
+			t.Fatal("expected an id")
+		}
+
+		byId, err := store.GetUserById(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		byEmail, err := store.GetUserByEmail(ctx, "a@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, u := range []*types.User{byId, byEmail} {
+			if u.Id != id || u.FirstName != "fname" || u.LastName != "lname" || u.Email != "a@example.com" ||
+				u.Password != "hash" || u.UType != types.UTypeRecruiter || u.Company != "Acme" || u.CreatedAt.IsZero() {
+				t.Errorf("unexpected user %+v", u)
+			}
+		}
+	})
+
+	t.Run("check email exists", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		exists, err := store.CheckUserWithEmailExits(ctx, "b@example.com")
+		if err != nil || exists {
+			t.Fatalf("expected no user, got %v (%v)", exists, err)
+		}
+
+		if _, err := store.CreateUser(ctx, newUser("b@example.com")); err != nil {
+			t.Fatal(err)
+		}
+
+		exists, err = store.CheckUserWithEmailExits(ctx, "b@example.com")
+		if err != nil || !exists {
+			t.Fatalf("expected user to exist, got %v (%v)", exists, err)
+		}
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		if u, err := store.GetUserById(ctx, 12345); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %+v (%v)", u, err)
+		}
+		if u, err := store.GetUserByEmail(ctx, "missing@example.com"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %+v (%v)", u, err)
+		}
+	})
+
+	t.Run("email ignores case", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		id, err := store.CreateUser(ctx, newUser("Mixed@Example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := store.GetUserByEmail(ctx, "mixed@example.COM")
+		if err != nil || u.Id != id {
+			t.Fatalf("expected user %d, got %+v (%v)", id, u, err)
+		}
+		if exists, err := store.CheckUserWithEmailExits(ctx, "MIXED@example.com"); err != nil || !exists {
+			t.Errorf("expected user to exist, got %v (%v)", exists, err)
+		}
+		if _, err := store.CreateUser(ctx, newUser("mixed@example.com")); !errors.Is(err, types.ErrDuplicateEmail) {
+			t.Errorf("expected ErrDuplicateEmail, got %v", err)
+		}
+	})
+
+	t.Run("link identity", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		id, err := store.CreateUser(ctx, newUser("d@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := store.CreateUser(ctx, newUser("e@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if u, err := store.GetUserByIdentity(ctx, "https://idp.example.com", "sub-1"); !errors.Is(err, types.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %+v (%v)", u, err)
+		}
+		if err := store.LinkIdentity(ctx, id, "https://idp.example.com", "sub-1"); err != nil {
+			t.Fatal(err)
+		}
+		// relinking keeps the first link
+		if err := store.LinkIdentity(ctx, other, "https://idp.example.com", "sub-1"); err != nil {
+			t.Fatal(err)
+		}
+		u, err := store.GetUserByIdentity(ctx, "https://idp.example.com", "sub-1")
+		if err != nil || u.Id != id || u.Email != "d@example.com" {
+			t.Fatalf("expected user %d, got %+v (%v)", id, u, err)
+		}
+
+		// subjects are scoped to their issuer
+		if u, err := store.GetUserByIdentity(ctx, "https://other.example.com", "sub-1"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %+v (%v)", u, err)
+		}
+		if err := store.LinkIdentity(ctx, 12345, "https://idp.example.com", "sub-2"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a missing user, got %v", err)
+		}
+	})
+
+	t.Run("duplicate email", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		if _, err := store.CreateUser(ctx, newUser("c@example.com")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.CreateUser(ctx, newUser("c@example.com")); !errors.Is(err, types.ErrDuplicateEmail) {
+			t.Errorf("expected ErrDuplicateEmail, got %v", err)
+		}
+	})
+
+	t.Run("concurrent creates with the same email", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		const attempts = 10
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		succeeded := 0
+		start := make(chan struct{})
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				_, err := store.CreateUser(ctx, newUser("race@example.com"))
+				if err != nil && !errors.Is(err, types.ErrDuplicateEmail) {
+					t.Errorf("expected ErrDuplicateEmail, got %v", err)
+				}
+				if err == nil {
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		if succeeded != 1 {
+			t.Errorf("expected exactly one insert to win, got %d", succeeded)
+		}
+		if _, err := store.GetUserByEmail(ctx, "race@example.com"); err != nil {
+			t.Errorf("expected the winning user to be stored, got %v", err)
+		}
+	})
+
+	t.Run("search users", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		create := func(first, last, email, uType, branch string) int {
+			t.Helper()
+			u := newUser(email)
+			u.FirstName, u.LastName, u.UType, u.Branch = first, last, uType, branch
+			id, err := store.CreateUser(ctx, u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return id
+		}
+		ada := create("Ada", "Lovelace", "ada@college.edu", types.UTypeStudent, "CSE")
+		alan := create("Alan", "Turing", "alan@college.edu", types.UTypeStudent, "ECE")
+		grace := create("Grace", "Hopper", "grace@navy.mil", types.UTypeRecruiter, "")
+		percent := create("Per", "Cent", "100%@college.edu", types.UTypeStudent, "cse")
+		gone := create("Gone", "Away", "gone@college.edu", types.UTypeStudent, "CSE")
+		if err := store.SetUserDisabled(ctx, alan, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.DeleteUser(ctx, gone); err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name   string
+			filter types.UserFilter
+			want   []int
+		}{
+			{name: "default hides deleted, newest first", filter: types.UserFilter{}, want: []int{percent, grace, alan, ada}},
+			{name: "full name", filter: types.UserFilter{Query: "ada love", Sort: types.UserSortName}, want: []int{ada}},
+			{name: "email ignores case", filter: types.UserFilter{Query: "NAVY", Sort: types.UserSortName}, want: []int{grace}},
+			{name: "like wildcards are literal", filter: types.UserFilter{Query: "0%", Sort: types.UserSortName}, want: []int{percent}},
+			{name: "role", filter: types.UserFilter{UType: types.UTypeRecruiter}, want: []int{grace}},
+			{name: "branch ignores case", filter: types.UserFilter{Branch: "CSE", Sort: types.UserSortEmail}, want: []int{percent, ada}},
+			{name: "active", filter: types.UserFilter{Status: types.UserStatusActive, Sort: types.UserSortName}, want: []int{ada, grace, percent}},
+			{name: "disabled", filter: types.UserFilter{Status: types.UserStatusDisabled}, want: []int{alan}},
+			{name: "deleted", filter: types.UserFilter{Status: types.UserStatusDeleted}, want: []int{gone}},
+			{name: "name descending", filter: types.UserFilter{Sort: "-" + types.UserSortName}, want: []int{percent, grace, alan, ada}},
+			{name: "page", filter: types.UserFilter{Sort: types.UserSortEmail, Limit: 2, Offset: 1}, want: []int{ada, alan}},
+			{name: "past the end", filter: types.UserFilter{Offset: 10}, want: []int{}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				users, err := store.SearchUsers(ctx, tt.filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := []int{}
+				for _, u := range users {
+					got = append(got, u.Id)
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("expected users %v, got %v", tt.want, got)
+				}
+			})
+		}
+
+		if _, err := store.SearchUsers(ctx, types.UserFilter{Sort: "password"}); err == nil {
+			t.Error("expected an unknown sort to fail")
+		}
+	})
+
+	t.Run("disable and enable", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		id, err := store.CreateUser(ctx, newUser("f@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SetUserDisabled(ctx, id, true); err != nil {
+			t.Fatal(err)
+		}
+		u, err := store.GetUserById(ctx, id)
+		if err != nil || u.DisabledAt == nil {
+			t.Fatalf("expected a disabled user, got %+v (%v)", u, err)
+		}
+		// disabling twice keeps the original time
+		if err := store.SetUserDisabled(ctx, id, true); err != nil {
+			t.Fatal(err)
+		}
+		if again, err := store.GetUserById(ctx, id); err != nil || again.DisabledAt == nil || !again.DisabledAt.Equal(*u.DisabledAt) {
+			t.Errorf("expected disabledAt %v, got %+v (%v)", u.DisabledAt, again, err)
+		}
+
+		if err := store.SetUserDisabled(ctx, id, false); err != nil {
+			t.Fatal(err)
+		}
+		if u, err := store.GetUserById(ctx, id); err != nil || u.DisabledAt != nil {
+			t.Errorf("expected an enabled user, got %+v (%v)", u, err)
+		}
+	})
+
+	t.Run("role and password", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		id, err := store.CreateUser(ctx, newUser("g@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SetUserRole(ctx, id, types.UTypeOfficer, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.RequirePasswordChange(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+		u, err := store.GetUserById(ctx, id)
+		if err != nil || u.UType != types.UTypeOfficer || u.Company != "" || !u.PasswordChangeRequired || u.PasswordResetAt == nil {
+			t.Fatalf("unexpected user %+v (%v)", u, err)
+		}
+		resetAt := *u.PasswordResetAt
+
+		// a second reset replaces the first
+		time.Sleep(time.Millisecond)
+		if err := store.RequirePasswordChange(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+		if u, err := store.GetUserById(ctx, id); err != nil || u.PasswordResetAt == nil || !u.PasswordResetAt.After(resetAt) {
+			t.Fatalf("expected a later reset than %v, got %+v (%v)", resetAt, u, err)
+		}
+
+		if err := store.UpdatePassword(ctx, id, "new-hash"); err != nil {
+			t.Fatal(err)
+		}
+		if u, err := store.GetUserById(ctx, id); err != nil || u.Password != "new-hash" || u.PasswordChangeRequired || u.PasswordResetAt != nil {
+			t.Errorf("expected the new password without a required change, got %+v (%v)", u, err)
+		}
+	})
+
+	t.Run("delete user", func(t *testing.T) {
+		t.Parallel()
+		store := newStore(t)
+
+		id, err := store.CreateUser(ctx, newUser("h@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.LinkIdentity(ctx, id, "https://idp.example.com", "sub-h"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.DeleteUser(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+
+		if u, err := store.GetUserById(ctx, id); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %+v (%v)", u, err)
+		}
+		if u, err := store.GetUserByEmail(ctx, "h@example.com"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %+v (%v)", u, err)
+		}
+		if u, err := store.GetUserByIdentity(ctx, "https://idp.example.com", "sub-h"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %+v (%v)", u, err)
+		}
+		if exists, err := store.CheckUserWithEmailExits(ctx, "h@example.com"); err != nil || !exists {
+			t.Errorf("expected the email to stay taken, got %v (%v)", exists, err)
+		}
+
+		for name, update := range map[string]func() error{
+			"delete":   func() error { return store.DeleteUser(ctx, id) },
+			"disable":  func() error { return store.SetUserDisabled(ctx, id, true) },
+			"role":     func() error { return store.SetUserRole(ctx, id, types.UTypeAdmin, "") },
+			"reset":    func() error { return store.RequirePasswordChange(ctx, id) },
+			"password": func() error { return store.UpdatePassword(ctx, id, "hash") },
+			"missing":  func() error { return store.SetUserDisabled(ctx, 12345, true) },
+		} {
+			if err := update(); !errors.Is(err, types.ErrNotFound) {
+				t.Errorf("%s: expected ErrNotFound, got %v", name, err)
+			}
+		}
+	})
+}
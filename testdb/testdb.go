@@ -0,0 +1,170 @@
+// Package testdb gives integration tests a migrated Postgres schema of their
+// own. Tests run against TEST_DATABASE_URL, or a throwaway cluster started
+// with initdb when that is unset, and are skipped when neither is available.
+package testdb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/cmd/migrate/migrations"
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	baseUrl   string
+	skipCause = "set TEST_DATABASE_URL or put initdb and pg_ctl on the PATH to run integration tests"
+)
+
+// Main runs the package's tests and must be called from TestMain. It starts a
+// disposable cluster if needed and removes it afterwards.
+func Main(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	flag.Parse()
+	baseUrl = os.Getenv("TEST_DATABASE_URL")
+	if baseUrl == "" && !testing.Short() {
+		stop, err := startCluster()
+		if err != nil {
+			skipCause = "could not start a local Postgres: " + err.Error()
+		} else {
+			defer stop()
+		}
+	}
+	return m.Run()
+}
+
+// New returns a pool whose search_path points at a fresh schema with every
+// migration applied. The schema is dropped when the test ends, so tests never
+// see each other's rows and may use as many connections as they like.
+func New(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+	dbUrl := NewSchema(t)
+	if err := migrations.Up(dbUrl); err != nil {
+		t.Fatalf("migrating test schema: %v", err)
+	}
+
+	pool, err := db.NewDbPool(config.DatabaseConfig{Url: dbUrl, MaxConns: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// NewSchema creates an empty schema that is dropped when the test ends and
+// returns a url whose search_path points at it, for tests that run
+// migrations themselves.
+func NewSchema(t testing.TB) string {
+	t.Helper()
+	if baseUrl == "" || testing.Short() {
+		t.Skip(skipCause)
+	}
+
+	ctx := context.Background()
+	schema := "test_" + randomSuffix(t)
+
+	admin, err := pgx.Connect(ctx, baseUrl)
+	if err != nil {
+		t.Fatalf("connecting to test database: %v", err)
+	}
+	defer admin.Close(ctx)
+	if _, err := admin.Exec(ctx, "create schema "+schema); err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+	t.Cleanup(func() {
+		conn, err := pgx.Connect(context.Background(), baseUrl)
+		if err != nil {
+			t.Errorf("connecting to drop schema %s: %v", schema, err)
+			return
+		}
+		defer conn.Close(context.Background())
+		if _, err := conn.Exec(context.Background(), "drop schema "+schema+" cascade"); err != nil {
+			t.Errorf("dropping schema %s: %v", schema, err)
+		}
+	})
+
+	u, err := url.Parse(baseUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func randomSuffix(t testing.TB) string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// startCluster initialises and starts a throwaway cluster in a temp dir,
+// listening on a free local port.
+func startCluster() (func(), error) {
+	initdb, err := exec.LookPath("initdb")
+	if err != nil {
+		return nil, err
+	}
+	pgCtl, err := exec.LookPath("pg_ctl")
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "testdb")
+	if err != nil {
+		return nil, err
+	}
+	data := filepath.Join(dir, "data")
+
+	if out, err := exec.Command(initdb, "-D", data, "-U", "postgres", "-A", "trust", "--no-sync").CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("initdb: %v: %s", err, out)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	opts := fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -c fsync=off", port, dir)
+	start := exec.Command(pgCtl, "-D", data, "-o", opts, "-l", filepath.Join(dir, "postgres.log"), "-w", "start")
+	if out, err := start.CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("pg_ctl start: %v: %s", err, out)
+	}
+
+	baseUrl = "postgres://postgres@127.0.0.1:" + strconv.Itoa(port) + "/postgres?sslmode=disable"
+	return func() {
+		exec.Command(pgCtl, "-D", data, "-m", "immediate", "stop").Run()
+		os.RemoveAll(dir)
+	}, nil
+}
+
+func freePort() (int, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	router := chi.NewRouter()
+	router.Use(Middleware)
+	router.Post("/register", func(w http.ResponseWriter, r *http.Request) {
+		_, span := Tracer().Start(r.Context(), "bcrypt.hash")
+		span.End()
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/register", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	child, server := spans[0], spans[1]
+	if server.Name() != "POST /register" {
+		t.Errorf("expected span to be named after the route, got %s", server.Name())
+	}
+	if server.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Error("expected server span to continue the incoming trace")
+	}
+	if server.Status().Code.String() != "Error" {
+		t.Errorf("expected 5xx to mark the span as failed, got %s", server.Status().Code)
+	}
+	if child.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Error("expected handler span to be a child of the server span")
+	}
+}
+
+func TestMiddlewareOmitsPath(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	router := chi.NewRouter()
+	router.Use(Middleware)
+	router.Get("/calendar/feed/{token}.ics", func(w http.ResponseWriter, r *http.Request) {})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/calendar/feed/secret-feed-token.ics", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	route := false
+	for _, attr := range spans[0].Attributes() {
+		if strings.Contains(attr.Value.Emit(), "secret-feed-token") {
+			t.Errorf("expected the token to stay out of the span, got %s=%s", attr.Key, attr.Value.Emit())
+		}
+		if attr.Key == "http.route" && attr.Value.AsString() == "/calendar/feed/{token}.ics" {
+			route = true
+		}
+	}
+	if !route {
+		t.Errorf("expected the route pattern, got %v", spans[0].Attributes())
+	}
+	if strings.Contains(spans[0].Name(), "secret-feed-token") {
+		t.Errorf("expected the token to stay out of the span name, got %s", spans[0].Name())
+	}
+}
+
+func TestOperation(t *testing.T) {
+	if op := operation("\n\t\tSELECT id from users"); op != "select" {
+		t.Errorf("expected select, got %s", op)
+	}
+	if op := operation(""); op != "query" {
+		t.Errorf("expected query, got %s", op)
+	}
+}
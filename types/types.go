@@ -1,24 +1,213 @@
 package types
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	UTypeStudent   = "student"
+	UTypeRecruiter = "recruiter"
+	UTypeOfficer   = "officer"
+	UTypeAdmin     = "admin"
+)
+
+// Stores return these, possibly wrapped, so callers can tell a missing or
+// conflicting row apart from a failing database.
+var (
+	ErrNotFound       = errors.New("not found")
+	ErrDuplicateEmail = errors.New("email already in use")
+	// ErrInviteClosed is returned for invites that were accepted, revoked or
+	// have expired.
+	ErrInviteClosed = errors.New("invite is no longer pending")
+	// ErrSessionRevoked is returned for tokens whose session was signed out,
+	// has expired or never existed.
+	ErrSessionRevoked = errors.New("session revoked")
+	// ErrAlreadyApplied is returned for a second application of a student to
+	// the same drive.
+	ErrAlreadyApplied = errors.New("already applied to this drive")
+)
+
 type UserStore interface {
-	CheckUserWithEmailExits(email string) (bool, error)
-	GetUserByEmail(email string) (*User, error)
-	GetUserById(id int) (*User, error)
-	CreateUser(User) (int, error)
+	CheckUserWithEmailExits(ctx context.Context, email string) (bool, error)
+	GetUserByEmail(ctx context.Context, email string) (*User, error)
+	GetUserById(ctx context.Context, id int) (*User, error)
+	CreateUser(ctx context.Context, u User) (int, error)
+	// GetUserByIdentity finds the user linked to an external login, the
+	// subject is only unique per issuer.
+	GetUserByIdentity(ctx context.Context, issuer, subject string) (*User, error)
+	LinkIdentity(ctx context.Context, userId int, issuer, subject string) error
+
+	// Deleted users are only returned by SearchUsers, the other lookups and
+	// updates treat them as missing. Their email stays taken.
+	SearchUsers(ctx context.Context, filter UserFilter) ([]User, error)
+	SetUserDisabled(ctx context.Context, id int, disabled bool) error
+	SetUserRole(ctx context.Context, id int, uType, company string) error
+	// RequirePasswordChange also stamps PasswordResetAt, invalidating reset
+	// tokens issued before.
+	RequirePasswordChange(ctx context.Context, id int) error
+	// UpdatePassword also clears a required password change and its
+	// PasswordResetAt.
+	UpdatePassword(ctx context.Context, id int, hash string) error
+	DeleteUser(ctx context.Context, id int) error
 }
 
 type AuthService interface {
 	SignJwt(expirationTime time.Duration, claims CustomClaims) (string, error)
 	VerifyToken(tkn string) (*jwt.Token, error)
+	AccessTokenTTL() time.Duration
+	RefreshTokenTTL() time.Duration
+
+	// StartSession records a login, the returned id goes into every token
+	// issued for it.
+	StartSession(ctx context.Context, userId int, userAgent, ip string) (int, error)
+	// CheckSession fails with ErrSessionRevoked unless the claims belong to
+	// an active session, and keeps the session's last seen time current.
+	CheckSession(ctx context.Context, claims *CustomClaims) error
+	ListSessions(ctx context.Context, userId int) ([]Session, error)
+	RevokeSession(ctx context.Context, userId, sessionId int) error
+	RevokeSessions(ctx context.Context, userId int) error
+}
+
+// AuthStore keeps login sessions. Revoked sessions are kept for the record.
+type AuthStore interface {
+	CreateSession(ctx context.Context, s Session) (int, error)
+	// GetSession treats sessions of disabled or deleted users as missing, so
+	// their tokens stop working even if revoking their sessions failed.
+	GetSession(ctx context.Context, id int) (*Session, error)
+	// ListSessions returns the user's sessions that are not revoked and were
+	// created after since, newest first.
+	ListSessions(ctx context.Context, userId int, since time.Time) ([]Session, error)
+	TouchSession(ctx context.Context, id int, at time.Time) error
+	// RevokeSession fails with ErrNotFound unless the session belongs to the
+	// user and is not revoked yet.
+	RevokeSession(ctx context.Context, userId, id int) error
+	RevokeSessions(ctx context.Context, userId int) error
+}
+
+// OIDCProvider runs the authorization code flow with PKCE against an external
+// identity provider. Exchange verifies the returned ID token, including that
+// it carries nonce.
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	Exchange(ctx context.Context, code, verifier, nonce string) (*OIDCIdentity, error)
+}
+
+// OIDCIdentity holds the claims of a verified ID token that login cares about.
+type OIDCIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+}
+
+// InviteSigner issues and checks the signed tokens in invite links.
+type InviteSigner interface {
+	SignInvite(inviteId int, expiresAt time.Time) (string, error)
+	VerifyInvite(token string) (inviteId int, expiresAt time.Time, err error)
+}
+
+// PasswordResetSigner issues and checks the tokens users set their password
+// with after an admin reset it.
+type PasswordResetSigner interface {
+	SignPasswordReset(userId int, resetAt, expiresAt time.Time) (string, error)
+	VerifyPasswordReset(token string) (userId int, resetAt time.Time, err error)
+}
+
+type InviteStore interface {
+	CreateInvite(ctx context.Context, inv Invite) (int, error)
+	GetInvite(ctx context.Context, id int) (*Invite, error)
+	ListInvites(ctx context.Context, filter InviteFilter) ([]Invite, error)
+	// RenewInvite moves the expiry of a pending invite, which invalidates
+	// links issued before, and counts it as sent again.
+	RenewInvite(ctx context.Context, id int, expiresAt time.Time) error
+	RevokeInvite(ctx context.Context, id int) error
+	AcceptInvite(ctx context.Context, id int, userId int) error
+}
+
+type JobStore interface {
+	EnqueueJob(ctx context.Context, job Job) (int64, error)
+	ClaimJob(ctx context.Context, kinds []string, staleBefore time.Time) (*Job, error)
+	CompleteJob(ctx context.Context, id int64) error
+	RetryJob(ctx context.Context, id int64, runAt time.Time, lastError string) error
+	FailJob(ctx context.Context, id int64, lastError string) error
+	DeleteFinishedJobs(ctx context.Context, before time.Time) (int64, error)
+}
+
+type AuditStore interface {
+	AppendAuditEvent(ctx context.Context, e AuditEvent) error
+	ListAuditEvents(ctx context.Context, filter AuditEventFilter) ([]AuditEvent, error)
+	// EachAuditEvent calls fn with the events ListAuditEvents would return,
+	// one at a time without loading them all, and stops at fn's first error.
+	EachAuditEvent(ctx context.Context, filter AuditEventFilter, fn func(e AuditEvent) error) error
+	// VerifyAuditChain recomputes the hash chain and returns the id of the
+	// first event that doesn't match, or 0 if the chain is intact.
+	VerifyAuditChain(ctx context.Context) (int64, error)
+}
+
+type CalendarStore interface {
+	CreateFeedToken(ctx context.Context, userId int, tokenHash string) error
+	// GetUserIdByFeedToken treats feeds of disabled or deleted users as
+	// missing, so their calendars stop updating along with their account.
+	GetUserIdByFeedToken(ctx context.Context, tokenHash string) (int, error)
+	RevokeFeedTokens(ctx context.Context, userId int) error
+}
+
+// PlacementStore keeps the companies hiring through the placement cell, their
+// drives and the students' applications to them. Creating a row that points
+// at a missing one fails with ErrNotFound.
+type PlacementStore interface {
+	CreateCompany(ctx context.Context, c Company) (int, error)
+	GetCompanyByDomain(ctx context.Context, domain string) (*Company, error)
+	CreateDrive(ctx context.Context, d Drive) (int, error)
+	CreateApplication(ctx context.Context, a Application) (int, error)
+	SetApplicationStatus(ctx context.Context, id int, status string) error
+	// ListApplications returns the student's applications, oldest first.
+	ListApplications(ctx context.Context, studentId int) ([]Application, error)
+	CreateInterviewSlot(ctx context.Context, s InterviewSlot) (int, error)
+	CreateOffer(ctx context.Context, o Offer) (int, error)
+	// ListOffers returns the offers made on the student's applications,
+	// oldest first.
+	ListOffers(ctx context.Context, studentId int) ([]Offer, error)
+	UpsertStudentProfile(ctx context.Context, p StudentProfile) error
+	GetStudentProfile(ctx context.Context, userId int) (*StudentProfile, error)
+	// ListEligibleDrives returns the drives open to students of branch,
+	// ordered by application deadline.
+	ListEligibleDrives(ctx context.Context, branch string) ([]Drive, error)
+	// ListInterviews returns the interview slots booked for the student's
+	// applications, ordered by start time.
+	ListInterviews(ctx context.Context, studentId int) ([]Interview, error)
+}
+
+// Stores groups the stores a unit of work can span.
+type Stores struct {
+	User      UserStore
+	Auth      AuthStore
+	Calendar  CalendarStore
+	Audit     AuditStore
+	Job       JobStore
+	Invite    InviteStore
+	Placement PlacementStore
 }
 
-type AuthStore interface{}
+// Transactor runs fn with stores that share a single transaction. The
+// transaction commits when fn returns nil and is rolled back when it returns
+// an error or panics. fn may be retried, so it must only touch the stores.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(s Stores) error) error
+}
+
+// CalendarSource provides the calendar events relevant to a user, e.g.
+// application deadlines of eligible drives or booked interview slots.
+type CalendarSource interface {
+	UserEvents(ctx context.Context, userId int) ([]CalendarEvent, error)
+}
 
 type LoginUserPayload struct {
 	Email    string `json:"email" validate:"required,email"`
@@ -33,17 +222,76 @@ type RegisterUserPayload struct {
 }
 
 type User struct {
-	Id        int       `json:"id"`
-	FirstName string    `json:"firstName"`
-	LastName  string    `json:"lastName"`
-	Email     string    `json:"email"`
-	Password  string    `json:"-"`
-	CreatedAt time.Time `json:"createdAt"`
+	Id                     int        `json:"id"`
+	FirstName              string     `json:"firstName"`
+	LastName               string     `json:"lastName"`
+	Email                  string     `json:"email"`
+	Password               string     `json:"-"`
+	UType                  string     `json:"uType"`
+	Company                string     `json:"company,omitempty"`
+	Branch                 string     `json:"branch,omitempty"`
+	CreatedAt              time.Time  `json:"createdAt"`
+	DisabledAt             *time.Time `json:"disabledAt,omitempty"`
+	DeletedAt              *time.Time `json:"deletedAt,omitempty"`
+	PasswordChangeRequired bool       `json:"passwordChangeRequired"`
+	// PasswordResetAt is when an admin last required a password change, reset
+	// tokens name it so each one sets a password once.
+	PasswordResetAt *time.Time `json:"-"`
+}
+
+const (
+	UserStatusActive   = "active"
+	UserStatusDisabled = "disabled"
+	UserStatusDeleted  = "deleted"
+)
+
+// Sort orders for UserFilter, prefixed with - for descending.
+const (
+	UserSortCreatedAt = "createdAt"
+	UserSortName      = "name"
+	UserSortEmail     = "email"
+)
+
+// UserFilter searches users. Query matches part of the name or email, an
+// empty Status matches every user that is not deleted.
+type UserFilter struct {
+	Query  string
+	UType  string
+	Branch string
+	Status string
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+type UpdateRolePayload struct {
+	UType   string `json:"uType" validate:"required,oneof=student recruiter officer admin"`
+	Company string `json:"company" validate:"required_if=UType recruiter,max=255"`
+}
+
+type ChangePasswordPayload struct {
+	Email       string `json:"email" validate:"required,email"`
+	Password    string `json:"password" validate:"required"`
+	NewPassword string `json:"newPassword" validate:"required,min=8,max=130,password,nefield=Password"`
+}
+
+type ResetPasswordPayload struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"newPassword" validate:"required,min=8,max=130,password"`
+}
+
+// PasswordResetDto is the user an admin reset the password of, with the link
+// they set a new one through. The link is not stored.
+type PasswordResetDto struct {
+	User
+	Link      string    `json:"link"`
+	ExpiresAt time.Time `json:"linkExpiresAt"`
 }
 
 type CustomClaims struct {
 	Uid   int    `json:"uid"`
 	UType string `json:"uType"`
+	Sid   int    `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -53,4 +301,221 @@ type UserDto struct {
 	FirstName string `json:"firstName"`
 	LastName  string `json:"lastName"`
 	Email     string `json:"email"`
+	SessionId int    `json:"-"`
+}
+
+// Session is a single login, e.g. one browser on one device.
+type Session struct {
+	Id         int        `json:"id"`
+	UserId     int        `json:"userId"`
+	UserAgent  string     `json:"userAgent"`
+	Ip         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+type SessionDto struct {
+	Session
+	Current bool `json:"current"`
+}
+
+type Job struct {
+	Id          int64           `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"maxAttempts"`
+	RunAt       time.Time       `json:"runAt"`
+	UniqueKey   string          `json:"uniqueKey,omitempty"`
+	LastError   string          `json:"lastError,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+type CalendarEvent struct {
+	Uid         string    `json:"uid"`
+	Category    string    `json:"category"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	Url         string    `json:"url"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+type CalendarFeedDto struct {
+	Url string `json:"url"`
+}
+
+type AuditEvent struct {
+	Id         int64           `json:"id"`
+	ActorId    int             `json:"actorId"`
+	ActorRole  string          `json:"actorRole"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entityType"`
+	EntityId   string          `json:"entityId"`
+	Status     int             `json:"status"`
+	Changes    json.RawMessage `json:"changes,omitempty"`
+	Ip         string          `json:"ip"`
+	UserAgent  string          `json:"userAgent"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	PrevHash   string          `json:"prevHash"`
+	Hash       string          `json:"hash"`
+}
+
+type AuditEventFilter struct {
+	ActorId    int
+	Action     string
+	EntityType string
+	EntityId   string
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Offset     int
+}
+
+const (
+	InviteStatusPending  = "pending"
+	InviteStatusAccepted = "accepted"
+	InviteStatusRevoked  = "revoked"
+	InviteStatusExpired  = "expired"
+)
+
+// Invite lets an admin pre-assign the role and company of an account that is
+// created when the invite link is accepted.
+type Invite struct {
+	Id             int        `json:"id"`
+	Email          string     `json:"email"`
+	UType          string     `json:"uType"`
+	Company        string     `json:"company,omitempty"`
+	CreatedBy      int        `json:"createdBy"`
+	SendCount      int        `json:"sendCount"`
+	LastSentAt     time.Time  `json:"lastSentAt"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	AcceptedAt     *time.Time `json:"acceptedAt,omitempty"`
+	AcceptedUserId int        `json:"acceptedUserId,omitempty"`
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+func (i Invite) Status(now time.Time) string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InviteStatusAccepted
+	case i.RevokedAt != nil:
+		return InviteStatusRevoked
+	case !now.Before(i.ExpiresAt):
+		return InviteStatusExpired
+	default:
+		return InviteStatusPending
+	}
+}
+
+type InviteFilter struct {
+	Status string
+	Email  string
+	Limit  int
+	Offset int
+}
+
+type CreateInvitePayload struct {
+	Email   string `json:"email" validate:"required,email"`
+	UType   string `json:"uType" validate:"required,oneof=recruiter officer"`
+	Company string `json:"company" validate:"required_if=UType recruiter,max=255"`
+}
+
+type AcceptInvitePayload struct {
+	Token     string `json:"token" validate:"required"`
+	FirstName string `json:"firstName" validate:"required"`
+	LastName  string `json:"lastName" validate:"required"`
+	Password  string `json:"password" validate:"required,min=8,max=130,password"`
+}
+
+type InviteDto struct {
+	Invite
+	Status string `json:"status"`
+	// Link is only returned when the invite is created or resent, it is
+	// not stored
+	Link string `json:"link,omitempty"`
+}
+
+type Company struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+	// Domain is the email domain of the company's recruiters.
+	Domain    string    `json:"domain,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Drive is a company's hiring round. An empty EligibleBranches opens it to
+// every branch, TalkAt is the pre-placement talk if one is scheduled.
+type Drive struct {
+	Id                  int        `json:"id"`
+	CompanyId           int        `json:"companyId"`
+	CompanyName         string     `json:"companyName"`
+	Title               string     `json:"title"`
+	EligibleBranches    []string   `json:"eligibleBranches"`
+	ApplicationDeadline time.Time  `json:"applicationDeadline"`
+	TalkAt              *time.Time `json:"talkAt,omitempty"`
+	TalkLocation        string     `json:"talkLocation,omitempty"`
+	CreatedAt           time.Time  `json:"createdAt"`
+}
+
+const (
+	ApplicationStatusApplied     = "applied"
+	ApplicationStatusShortlisted = "shortlisted"
+	ApplicationStatusInterview   = "interview"
+	ApplicationStatusOffered     = "offered"
+	ApplicationStatusRejected    = "rejected"
+	ApplicationStatusWithdrawn   = "withdrawn"
+)
+
+type Application struct {
+	Id        int       `json:"id"`
+	DriveId   int       `json:"driveId"`
+	StudentId int       `json:"studentId"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type InterviewSlot struct {
+	Id            int       `json:"id"`
+	ApplicationId int       `json:"applicationId"`
+	StartsAt      time.Time `json:"startsAt"`
+	EndsAt        time.Time `json:"endsAt"`
+	Location      string    `json:"location"`
+}
+
+// Interview is a booked slot with the drive it is for.
+type Interview struct {
+	InterviewSlot
+	DriveId     int    `json:"driveId"`
+	DriveTitle  string `json:"driveTitle"`
+	CompanyName string `json:"companyName"`
+}
+
+const (
+	OfferStatusExtended = "extended"
+	OfferStatusAccepted = "accepted"
+	OfferStatusDeclined = "declined"
+)
+
+// Offer is made on an application, Ctc is the yearly cost to company in
+// rupees.
+type Offer struct {
+	Id            int       `json:"id"`
+	ApplicationId int       `json:"applicationId"`
+	Ctc           int64     `json:"ctc"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// StudentProfile holds the academic record placement eligibility is judged on.
+type StudentProfile struct {
+	UserId         int       `json:"userId"`
+	Cgpa           float64   `json:"cgpa"`
+	GraduationYear int       `json:"graduationYear"`
+	UpdatedAt      time.Time `json:"updatedAt"`
 }
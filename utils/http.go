@@ -2,17 +2,110 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/SufyaanKhateeb/college-placement-app-api/config"
+	"github.com/SufyaanKhateeb/college-placement-app-api/reqctx"
 )
 
-func ParseJson(r *http.Request, payload any) error {
-	if r.Body == nil {
-		return fmt.Errorf("missing request body")
+// DefaultMaxBodyBytes bounds request bodies decoded by ParseJson when no
+// MaxBodyBytes middleware set a limit and none is passed with WithMaxBytes.
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+type parseOptions struct {
+	maxBytes           int64
+	allowUnknownFields bool
+}
+
+type ParseOption func(*parseOptions)
+
+func WithMaxBytes(n int64) ParseOption {
+	return func(o *parseOptions) {
+		o.maxBytes = n
+	}
+}
+
+// AllowUnknownFields accepts fields in the body that payload does not declare.
+func AllowUnknownFields() ParseOption {
+	return func(o *parseOptions) {
+		o.allowUnknownFields = true
+	}
+}
+
+// ParseJson decodes a single JSON value from the request body into payload.
+// Errors are *Problem values carrying the status to respond with, so callers
+// can pass them straight to WriteJsonError.
+func ParseJson(r *http.Request, payload any, opts ...ParseOption) error {
+	o := parseOptions{maxBytes: DefaultMaxBodyBytes}
+	if n, ok := reqctx.MaxBodyBytes(r.Context()); ok {
+		o.maxBytes = n
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if r.Body == nil || r.Body == http.NoBody {
+		return NewProblem(http.StatusBadRequest, CodeInvalidJson, "request body must not be empty")
 	}
 
-	return json.NewDecoder(r.Body).Decode(payload)
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+		return NewProblem(http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, "content type must be application/json")
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, o.maxBytes))
+	if !o.allowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(payload); err != nil {
+		return decodeProblem(err)
+	}
+
+	// anything but EOF after the first value means trailing data
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return decodeProblem(err)
+		}
+		return NewProblem(http.StatusBadRequest, CodeInvalidJson, "request body must contain a single JSON value")
+	}
+
+	return nil
+}
+
+func decodeProblem(err error) *Problem {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return NewProblem(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, fmt.Sprintf("request body must not be larger than %d bytes", maxBytesErr.Limit))
+	case errors.As(err, &syntaxErr):
+		return NewProblem(http.StatusBadRequest, CodeInvalidJson, fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return NewProblem(http.StatusBadRequest, CodeInvalidJson, "malformed JSON, body ended unexpectedly")
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			return NewProblem(http.StatusBadRequest, CodeInvalidJson, fmt.Sprintf("field %q must be of type %s (offset %d)", typeErr.Field, typeErr.Type, typeErr.Offset))
+		}
+		return NewProblem(http.StatusBadRequest, CodeInvalidJson, fmt.Sprintf("body must be of type %s (offset %d)", typeErr.Type, typeErr.Offset))
+	case errors.Is(err, io.EOF):
+		return NewProblem(http.StatusBadRequest, CodeInvalidJson, "request body must not be empty")
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for this case
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return NewProblem(http.StatusBadRequest, CodeInvalidJson, fmt.Sprintf("unknown field %s", field))
+	default:
+		return NewProblem(http.StatusBadRequest, CodeInvalidJson, err.Error())
+	}
 }
 
 func WriteJson(w http.ResponseWriter, status int, payload any) error {
@@ -22,17 +115,28 @@ func WriteJson(w http.ResponseWriter, status int, payload any) error {
 	return json.NewEncoder(w).Encode(payload)
 }
 
+// WriteJsonError writes err as an application/problem+json response.
 func WriteJsonError(w http.ResponseWriter, status int, err error) error {
-	return WriteJson(w, status, map[string]string{"error": err.Error()})
+	return WriteProblem(w, ProblemFromError(status, err))
+}
+
+// WriteInternalError logs err with the request's logger under msg and
+// responds with a 500 that keeps the error from the client.
+func WriteInternalError(w http.ResponseWriter, r *http.Request, msg string, err error) error {
+	reqctx.Logger(r.Context()).Error(msg, "err", err)
+	return WriteJsonError(w, http.StatusInternalServerError, err)
 }
 
-func WriteJwtToCookie(w http.ResponseWriter, key string, token string, expirationTime time.Duration) {
+func WriteJwtToCookie(w http.ResponseWriter, key string, token string, expirationTime time.Duration, settings config.CookieConfig) {
 	cookie := &http.Cookie{
 		Name:     key,
 		Value:    token,
 		HttpOnly: true,
 		Expires:  time.Now().Add(expirationTime),
-		Path:     "/",
+		Path:     settings.Path,
+		Domain:   settings.Domain,
+		Secure:   settings.Secure,
+		SameSite: settings.SameSiteMode(),
 		MaxAge:   int(expirationTime.Seconds()),
 	}
 
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type parsePayload struct {
+	Email string `json:"email"`
+	Age   int    `json:"age"`
+}
+
+func TestParseJson(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		opts        []ParseOption
+		status      int
+		detail      string
+	}{
+		{name: "valid", contentType: "application/json", body: `{"email":"a@b.c","age":3}`},
+		{name: "charset parameter", contentType: "application/json; charset=utf-8", body: `{"age":3}`},
+		{name: "wrong content type", contentType: "text/plain", body: `{}`, status: http.StatusUnsupportedMediaType},
+		{name: "missing content type", body: `{}`, status: http.StatusUnsupportedMediaType},
+		{name: "empty body", contentType: "application/json", body: ``, status: http.StatusBadRequest, detail: "must not be empty"},
+		{name: "syntax error", contentType: "application/json", body: `{"email":}`, status: http.StatusBadRequest, detail: "offset 10"},
+		{name: "truncated", contentType: "application/json", body: `{"email":"a"`, status: http.StatusBadRequest, detail: "ended unexpectedly"},
+		{name: "wrong type", contentType: "application/json", body: `{"age":"three"}`, status: http.StatusBadRequest, detail: `field "age" must be of type int`},
+		{name: "unknown field", contentType: "application/json", body: `{"admin":true}`, status: http.StatusBadRequest, detail: `unknown field "admin"`},
+		{name: "unknown field allowed", contentType: "application/json", body: `{"admin":true}`, opts: []ParseOption{AllowUnknownFields()}},
+		{name: "multiple values", contentType: "application/json", body: `{} {}`, status: http.StatusBadRequest, detail: "single JSON value"},
+		{name: "too large", contentType: "application/json", body: `{"email":"` + strings.Repeat("a", 64) + `"}`, opts: []ParseOption{WithMaxBytes(16)}, status: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			var payload parsePayload
+			err := ParseJson(req, &payload, tt.opts...)
+			if tt.status == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+
+			var p *Problem
+			if !errors.As(err, &p) {
+				t.Fatalf("expected a problem, got %v", err)
+			}
+			if p.Status != tt.status {
+				t.Errorf("expected status %d, got %d (%s)", tt.status, p.Status, p.Detail)
+			}
+			if !strings.Contains(p.Detail, tt.detail) {
+				t.Errorf("expected detail to contain %q, got %q", tt.detail, p.Detail)
+			}
+		})
+	}
+}
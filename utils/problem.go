@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Error codes are part of the API contract, clients switch on them so they
+// must never change once released.
+const (
+	CodeBadRequest            = "bad_request"
+	CodeInvalidJson           = "invalid_json"
+	CodeValidationFailed      = "validation_failed"
+	CodeUnauthorized          = "unauthorized"
+	CodeForbidden             = "forbidden"
+	CodeNotFound              = "not_found"
+	CodeMethodNotAllowed      = "method_not_allowed"
+	CodeConflict              = "conflict"
+	CodePayloadTooLarge       = "payload_too_large"
+	CodeUnsupportedMediaType  = "unsupported_media_type"
+	CodeInvalidCredentials    = "invalid_credentials"
+	CodeEmailTaken            = "email_taken"
+	CodeEmailDomainNotAllowed = "email_domain_not_allowed"
+	CodeInviteInvalid         = "invite_invalid"
+	CodeInviteClosed          = "invite_closed"
+	CodeLoginStateInvalid     = "login_state_invalid"
+	CodeSSOFailed             = "sso_failed"
+	CodeSSONotLinked          = "sso_not_linked"
+	CodeEmailUnverified       = "email_unverified"
+	CodeAccountDisabled       = "account_disabled"
+	CodePasswordChangeNeeded  = "password_change_required"
+	CodePasswordResetInvalid  = "password_reset_invalid"
+	CodeInvalidQuery          = "invalid_query"
+	CodeInvalidId             = "invalid_id"
+	CodeUserNotFound          = "user_not_found"
+	CodeSessionNotFound       = "session_not_found"
+	CodeInviteNotFound        = "invite_not_found"
+	CodeFeedNotFound          = "calendar_feed_not_found"
+	CodeEventNotFound         = "calendar_event_not_found"
+	CodeTimeout               = "timeout"
+	CodeInternal              = "internal_error"
+	CodeUnavailable           = "service_unavailable"
+)
+
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusUnsupportedMediaType:  CodeUnsupportedMediaType,
+	http.StatusUnprocessableEntity:   CodeValidationFailed,
+	http.StatusGatewayTimeout:        CodeTimeout,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+}
+
+// Problem is an RFC 7807 problem details object. Code is a stable machine
+// readable identifier, Errors lists the offending fields of a request body.
+type Problem struct {
+	Type   string       `json:"type"`
+	Title  string       `json:"title"`
+	Status int          `json:"status"`
+	Code   string       `json:"code"`
+	Detail string       `json:"detail,omitempty"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	return p.Title
+}
+
+func NewProblem(status int, code string, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+// ProblemFromError converts err into a Problem. Errors that are not already a
+// Problem get the default code for status, and for server errors their text is
+// withheld so internals do not leak to clients.
+func ProblemFromError(status int, err error) *Problem {
+	var p *Problem
+	if errors.As(err, &p) {
+		return p
+	}
+
+	code, ok := statusCodes[status]
+	if !ok {
+		code = CodeBadRequest
+		if status >= 500 {
+			code = CodeInternal
+		}
+	}
+
+	detail := ""
+	if err != nil && status < 500 {
+		detail = err.Error()
+	}
+	return NewProblem(status, code, detail)
+}
+
+// ValidationProblem turns validator errors into a 400 problem with one entry
+// per invalid field, named after the field's json tag.
+func ValidationProblem(err error) *Problem {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return NewProblem(http.StatusBadRequest, CodeValidationFailed, err.Error())
+	}
+
+	p := NewProblem(http.StatusBadRequest, CodeValidationFailed, "one or more fields are invalid")
+	for _, fe := range verrs {
+		p.Errors = append(p.Errors, FieldError{
+			Field:   fe.Field(),
+			Code:    fe.Tag(),
+			Message: fieldMessage(fe),
+		})
+	}
+	return p
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "password":
+		return "must contain at least one letter, one number and one special character"
+	case "min":
+		if fe.Kind().String() == "string" {
+			return fmt.Sprintf("must be at least %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		if fe.Kind().String() == "string" {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "len":
+		return fmt.Sprintf("must be exactly %s characters long", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	case "url":
+		return "must be a valid URL"
+	default:
+		return fmt.Sprintf("failed the %s check", fe.Tag())
+	}
+}
+
+func WriteProblem(w http.ResponseWriter, p *Problem) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+
+	return json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidationProblem(t *testing.T) {
+	t.Parallel()
+	payload := struct {
+		Email    string `json:"email" validate:"required,email"`
+		Password string `json:"password" validate:"required,min=8,password"`
+	}{
+		Email:    "",
+		Password: "longenough",
+	}
+
+	p := ValidationProblem(GetValidator().Struct(payload))
+	if p.Status != http.StatusBadRequest || p.Code != CodeValidationFailed {
+		t.Fatalf("unexpected problem %+v", p)
+	}
+	if len(p.Errors) != 2 {
+		t.Fatalf("expected 2 field errors, got %+v", p.Errors)
+	}
+	if p.Errors[0].Field != "email" || p.Errors[0].Code != "required" {
+		t.Errorf("unexpected email error %+v", p.Errors[0])
+	}
+	if p.Errors[1].Field != "password" || !strings.Contains(p.Errors[1].Message, "special character") {
+		t.Errorf("unexpected password error %+v", p.Errors[1])
+	}
+}
+
+func TestWriteJsonErrorHidesServerErrors(t *testing.T) {
+	t.Parallel()
+	rr := httptest.NewRecorder()
+	WriteJsonError(rr, http.StatusInternalServerError, errors.New("pq: relation users does not exist"))
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("expected problem content type, got %q", ct)
+	}
+	body := rr.Body.String()
+	if strings.Contains(body, "relation") || !strings.Contains(body, `"code":"internal_error"`) {
+		t.Errorf("unexpected body %s", body)
+	}
+}
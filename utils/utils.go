@@ -1,15 +1,35 @@
 package utils
 
 import (
+	"reflect"
 	"regexp"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/unicode/norm"
 )
 
-var validate = validator.New()
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterValidation("password", validatePassword)
+
+	// report fields by the name clients send rather than the Go field name
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+	return v
+}
 
 func GetValidator() *validator.Validate {
-	validate.RegisterValidation("password", validatePassword)
 	return validate
 }
 
@@ -27,3 +47,9 @@ func validatePassword(fl validator.FieldLevel) bool {
 
 	return hasLetter && hasNumber && hasSpecialChar
 }
+
+// NormalizeEmail is applied to every email address before it is validated,
+// stored or looked up, so the same address always has the same spelling.
+func NormalizeEmail(email string) string {
+	return norm.NFC.String(strings.ToLower(strings.TrimSpace(email)))
+}
@@ -0,0 +1,19 @@
+package utils
+
+import "testing"
+
+func TestNormalizeEmail(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"  Alice@College.EDU ": "alice@college.edu",
+		"bob@college.edu":      "bob@college.edu",
+		// e followed by a combining acute accent composes into é
+		"René@college.edu": "rené@college.edu",
+		"ÉLISE@college.edu": "élise@college.edu",
+	}
+	for in, want := range tests {
+		if got := NormalizeEmail(in); got != want {
+			t.Errorf("NormalizeEmail(%q) = %q, want %q", in, got, want)
+		}
+	}
+}